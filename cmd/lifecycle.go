@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"ice/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// lifecycle stops components in the reverse order they were registered,
+// so a component is always stopped before the ones it depends on:
+// HTTP stops taking requests, then workers drain, then adapters close
+type lifecycle struct {
+	mu    sync.Mutex
+	hooks []shutdownHook
+}
+
+type shutdownHook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// OnShutdown registers stop to run during Shutdown
+func (l *lifecycle) OnShutdown(name string, stop func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, shutdownHook{name: name, stop: stop})
+}
+
+// Shutdown runs every hook once, last registered first. A failing hook is
+// logged and does not prevent the remaining ones from running.
+func (l *lifecycle) Shutdown(ctx context.Context) {
+	l.mu.Lock()
+	hooks := l.hooks
+	l.hooks = nil
+	l.mu.Unlock()
+
+	log := logger.Get()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		log.Info("Stopping component", zap.String("component", h.name))
+		if err := h.stop(ctx); err != nil {
+			log.Error("Error stopping component", zap.String("component", h.name), zap.Error(err))
+		}
+	}
+}
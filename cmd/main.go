@@ -20,13 +20,23 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // IANA zones for todo time zones on images without them
 
 	"ice/config"
 	"ice/internal/adapter/mysql"
 	"ice/internal/adapter/redis"
+	auditrepo "ice/internal/audit/repository"
+	calendarrepo "ice/internal/calendar/repository"
+	calendarservice "ice/internal/calendar/service"
+	"ice/internal/event"
 	"ice/internal/handler/http"
+	"ice/internal/health"
+	"ice/internal/outbox"
 	outboxrepo "ice/internal/outbox/repository"
 	outboxservice "ice/internal/outbox/service"
+	"ice/internal/port"
+	reminderrepo "ice/internal/reminder/repository"
+	reminderservice "ice/internal/reminder/service"
 	"ice/internal/todo/repository"
 	"ice/internal/todo/service"
 	"ice/pkg/logger"
@@ -51,7 +61,12 @@ func main() {
 	log := logger.Get()
 	cfg := config.Load()
 
-	// Run migrations
+	// Migration subcommands
+	if flag.Arg(0) == "migrate" {
+		os.Exit(runMigrate(cfg.MySQL, flag.Args()[1:]))
+	}
+
+	// Run migrations, same as "migrate up"
 	if *migrateFlag {
 		if err := migrator.RunMigrations(cfg.MySQL); err != nil {
 			log.Fatal("migration failed", zap.Error(err))
@@ -59,11 +74,42 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Components register their shutdown as they start, shutdown runs in
+	// reverse: HTTP, trash purger, reminder scheduler, outbox processor,
+	// Redis, MySQL
+	var lc lifecycle
+
 	// Initialize MySQL
 	mysqlAdapter, err := mysql.NewMySQL(cfg.MySQL)
 	if err != nil {
 		log.Fatal("failed to initialize mysql adapter", zap.Error(err))
 	}
+	lc.OnShutdown("mysql", func(context.Context) error {
+		return mysqlAdapter.Close()
+	})
+
+	// Schema
+	if cfg.MySQL.MigrateOnStart {
+		log.Info("Applying migrations")
+		if err := migrateOnStart(context.Background(), cfg.MySQL, mysqlAdapter); err != nil {
+			mysqlAdapter.Close()
+			log.Fatal("migration on start failed", zap.Error(err))
+		}
+	}
+	schemaVersion, err := migrator.LatestVersion(cfg.MySQL.MigrationsPath)
+	if err != nil {
+		mysqlAdapter.Close()
+		log.Fatal("failed to read migrations", zap.Error(err))
+	}
+	if cfg.MySQL.SchemaCheck == schemaCheckStrict {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Health.CheckTimeout)
+		err := health.Migration(mysqlAdapter.DB(), schemaVersion).Check(ctx)
+		cancel()
+		if err != nil {
+			mysqlAdapter.Close()
+			log.Fatal("refusing to serve with an outdated schema, run migrations first", zap.Error(err))
+		}
+	}
 
 	// Initialize Redis
 	redisCli, err := redis.NewRedisStreamClient(cfg.Redis)
@@ -71,27 +117,93 @@ func main() {
 		mysqlAdapter.Close()
 		log.Fatal("failed to initialize redis adapter", zap.Error(err))
 	}
+	lc.OnShutdown("redis", func(context.Context) error {
+		return redisCli.Close()
+	})
+
 	outboxRepo := outboxrepo.NewRepository(mysqlAdapter)
-	outboxService := outboxservice.NewService(outboxRepo, redisCli)
+	var outboxNotifier port.OutboxNotifier
+	if cfg.Outbox.NotifyChannel != "" {
+		outboxNotifier = redis.NewOutboxNotifier(redisCli.Client(), cfg.Outbox.NotifyChannel)
+	}
+	schemaRegistry, err := event.NewRegistry()
+	if err != nil {
+		log.Fatal("failed to load event schemas", zap.Error(err))
+	}
+	eventEncoder, err := event.NewEncoder(cfg.Outbox.Codec, cfg.Outbox.TopicCodecs)
+	if err != nil {
+		log.Fatal("failed to load event codecs", zap.Error(err))
+	}
+	outboxService := outboxservice.NewService(outboxservice.Dependencies{
+		Repo:      outboxRepo,
+		Publisher: redisCli,
+		Tx:        mysqlAdapter,
+		Locker:    mysqlAdapter,
+		Notifier:  outboxNotifier,
+		Validator: schemaRegistry,
+		Encoder:   eventEncoder,
+	}, cfg.Outbox)
 	// Initialize Repository + Service
 	TodoRepository := repository.NewRepository(mysqlAdapter)
-	todoService := service.NewService(TodoRepository, outboxService)
+	auditRepository := auditrepo.NewRepository(mysqlAdapter)
+	todoService := service.NewService(TodoRepository, outboxService, mysqlAdapter, auditRepository)
+	calendarService := calendarservice.NewService(calendarrepo.NewRepository(mysqlAdapter))
 
-	// ---------------------------------------
-	// NEW: Outbox Processor Context + Goroutine
-	// ---------------------------------------
-	outboxCtx, outboxCancel := context.WithCancel(context.Background())
-	outboxService.StartProcessor(outboxCtx)
+	// Outbox Processor
+	outboxProcessor := outboxService.StartProcessor(context.Background())
+	lc.OnShutdown("outbox processor", outboxProcessor.Stop)
 	log.Info("Outbox processor started")
 
-	// ---------------------------------------
+	// Reminder Scheduler
+	if cfg.Reminder.Enabled {
+		reminderService := reminderservice.NewService(reminderservice.Dependencies{
+			Repo:   reminderrepo.NewRepository(mysqlAdapter),
+			Outbox: outboxService,
+			Tx:     mysqlAdapter,
+			Locker: mysqlAdapter,
+		}, cfg.Reminder)
+		reminderScheduler := reminderService.StartScheduler(context.Background())
+		lc.OnShutdown("reminder scheduler", reminderScheduler.Stop)
+		log.Info("Reminder scheduler started")
+	}
+
+	// Trash Purger
+	if cfg.Trash.Retention > 0 {
+		purger := service.NewPurger(service.PurgerDependencies{
+			Repo:   TodoRepository,
+			Outbox: outboxService,
+			Tx:     mysqlAdapter,
+			Locker: mysqlAdapter,
+			Audit:  auditRepository,
+		}, cfg.Trash)
+		purgeJob := purger.Start(context.Background())
+		lc.OnShutdown("trash purger", purgeJob.Stop)
+		log.Info("Trash purger started", zap.Duration("retention", cfg.Trash.Retention))
+	}
+
 	// HTTP Server
-	// ---------------------------------------
+	outboxThresholds := outbox.Thresholds{
+		MaxLag:     cfg.Health.OutboxMaxLag,
+		MaxPending: cfg.Health.OutboxMaxPending,
+		MaxFailed:  cfg.Health.OutboxMaxFailed,
+	}
 	server := http.NewServer(http.ServerDependencies{
 		TodoService: todoService,
-		MySQL:       mysqlAdapter.DB(),
-		Redis:       redisCli.Client(),
+		TodoLimits: http.TodoLimits{
+			BatchMaxOperations: cfg.HTTP.BatchMaxOperations,
+			ImportMaxRows:      cfg.HTTP.ImportMaxRows,
+			BatchMaxBytes:      cfg.HTTP.BatchMaxBytes,
+			ImportMaxBytes:     cfg.HTTP.ImportMaxBytes,
+		},
+		CalendarService:  calendarService,
+		Health:           newHealthRegistry(cfg.Health, cfg.MySQL.SchemaCheck, schemaVersion, mysqlAdapter, redisCli, outboxService, outboxThresholds),
+		Outbox:           outboxService,
+		OutboxThresholds: outboxThresholds,
+		PublicURL:        cfg.HTTP.PublicURL,
+		TrustedProxies:   cfg.HTTP.TrustedProxies,
+		GatewayToken:     cfg.HTTP.GatewayToken,
 	}, cfg.HTTP.Port)
+	lc.OnShutdown("http server", server.Shutdown)
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -104,30 +216,32 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// ---------------------------------------
-	// NEW: Stop Outbox Processor
-	// ---------------------------------------
-	log.Info("Stopping Outbox processor...")
-	outboxCancel()
-	// Optional: small sleep to give goroutine time to stop cleanly
-	time.Sleep(200 * time.Millisecond)
-
-	// Shutdown HTTP server
-	if err := server.Shutdown(ctx); err != nil {
-		log.Error("Error shutting down HTTP server", zap.Error(err))
-	}
-
-	log.Info("Closing components...")
+	lc.Shutdown(ctx)
 
-	// Close Redis
-	if err := redisCli.Close(); err != nil {
-		log.Error("Error closing Redis connection", zap.Error(err))
-	}
+	log.Info("Server exited gracefully")
+}
 
-	// Close MySQL
-	if err := mysqlAdapter.Close(); err != nil {
-		log.Error("Error closing MySQL connection", zap.Error(err))
+// newHealthRegistry wires the dependency checks into the probes. Only
+// MySQL and, unless disabled, the schema version gate readiness: while
+// Redis is down the outbox keeps buffering events, so restarting the pod
+// would not help.
+func newHealthRegistry(cfg config.HealthConfig, schemaCheck string, schemaVersion uint, db *mysql.MySQL, redisCli *redis.RedisStreamClient, monitor port.OutboxMonitor, thresholds outbox.Thresholds) *health.Registry {
+	registry := health.NewRegistry(cfg.CheckTimeout, cfg.CacheTTL)
+
+	registry.Register(health.MySQL(db.DB()), health.Options{Critical: true}, health.Readiness, health.Startup)
+	registry.Register(health.Redis(redisCli.Client()), health.Options{}, health.Readiness)
+	registry.Register(health.Outbox(monitor, thresholds), health.Options{}, health.Readiness)
+
+	switch schemaCheck {
+	case schemaCheckOff:
+	case schemaCheckStrict:
+		registry.Register(health.Migration(db.DB(), schemaVersion), health.Options{Critical: true}, health.Startup)
+	default:
+		if schemaCheck != schemaCheckReadiness {
+			logger.Get().Warn("unknown schema check mode, using readiness", zap.String("mode", schemaCheck))
+		}
+		registry.Register(health.Migration(db.DB(), schemaVersion), health.Options{Critical: true}, health.Readiness, health.Startup)
 	}
 
-	log.Info("Server exited gracefully")
+	return registry
 }
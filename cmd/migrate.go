@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"ice/config"
+	"ice/internal/port"
+	"ice/pkg/migrator"
+)
+
+// Schema check modes, see config.MySQLConfig.SchemaCheck
+const (
+	schemaCheckStrict    = "strict"
+	schemaCheckReadiness = "readiness"
+	schemaCheckOff       = "off"
+)
+
+// migrateLock serialises migrate-on-start across instances
+const migrateLock = "ice.migrate"
+
+// migrateOnStart applies pending migrations while holding a MySQL lock, so
+// instances starting together migrate once and the others wait for it
+func migrateOnStart(ctx context.Context, cfg config.MySQLConfig, locker port.Locker) error {
+	release, ok, err := locker.Lock(ctx, migrateLock, cfg.MigrateLockTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("timed out after %s waiting for another instance to migrate", cfg.MigrateLockTimeout)
+	}
+	defer release()
+
+	return migrator.RunMigrations(cfg)
+}
+
+const migrateUsage = `usage: migrate [-yes] <command>
+
+commands:
+  up          apply all pending migrations
+  down        revert all migrations (destructive)
+  steps N     apply N migrations, or revert -N when negative (destructive when negative)
+  goto V      migrate up or down to version V (destructive when going down)
+  force V     mark version V as applied and clean without running it, -1 for none
+  version     print the applied version
+  status      list shipped migrations and whether they are applied
+`
+
+// runMigrate implements the migrate subcommand and returns the process
+// exit code: 0 on success, 1 on failure and 2 on misuse
+func runMigrate(cfg config.MySQLConfig, args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	yes := flags.Bool("yes", false, "skip the confirmation of destructive commands")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	cmd, arg, err := parseMigrateArgs(flags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n\n%s", err, migrateUsage)
+		return 2
+	}
+
+	m, err := migrator.New(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer m.Close()
+
+	version, dirty, err := m.Version()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if prompt := destructive(cmd, arg, version); prompt != "" && !*yes {
+		if !confirm(os.Stdin, os.Stderr, prompt) {
+			fmt.Fprintln(os.Stderr, "aborted")
+			return 1
+		}
+	}
+
+	switch cmd {
+	case "up":
+		err = m.Up()
+	case "down":
+		err = m.Down()
+	case "steps":
+		err = m.Steps(arg)
+	case "goto":
+		err = m.Goto(uint(arg))
+	case "force":
+		err = m.Force(arg)
+	case "version":
+		printVersion(version, dirty)
+		if dirty {
+			fmt.Fprintln(os.Stderr, "DIRTY:", &migrator.DirtyError{Version: version})
+			return 1
+		}
+		return 0
+	case "status":
+		return printStatus(m)
+	}
+
+	if err != nil {
+		var dirtyErr *migrator.DirtyError
+		if errors.As(err, &dirtyErr) {
+			fmt.Fprintln(os.Stderr, "DIRTY:", err)
+		} else {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return 1
+	}
+
+	version, dirty, err = m.Version()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	printVersion(version, dirty)
+	return 0
+}
+
+func parseMigrateArgs(args []string) (cmd string, arg int, err error) {
+	if len(args) == 0 {
+		return "", 0, fmt.Errorf("missing command")
+	}
+
+	cmd = args[0]
+	want := 0
+	switch cmd {
+	case "up", "down", "version", "status":
+	case "steps", "goto", "force":
+		want = 1
+	default:
+		return "", 0, fmt.Errorf("unknown command %q", cmd)
+	}
+	if len(args)-1 != want {
+		return "", 0, fmt.Errorf("%s takes %d argument(s)", cmd, want)
+	}
+	if want == 0 {
+		return cmd, 0, nil
+	}
+
+	arg, err = strconv.Atoi(args[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("%s: %q is not a number", cmd, args[1])
+	}
+	switch {
+	case cmd == "steps" && arg == 0:
+		return "", 0, fmt.Errorf("steps must not be 0")
+	case cmd == "goto" && arg < 0:
+		return "", 0, fmt.Errorf("goto needs a version")
+	case cmd == "force" && arg < -1:
+		return "", 0, fmt.Errorf("force takes a version or -1")
+	}
+	return cmd, arg, nil
+}
+
+// destructive describes what cmd is about to do when it can lose data or
+// hide a broken schema, and returns "" otherwise
+func destructive(cmd string, arg int, current uint) string {
+	switch {
+	case cmd == "down":
+		return fmt.Sprintf("This reverts every migration from version %d and drops all data.", current)
+	case cmd == "steps" && arg < 0:
+		return fmt.Sprintf("This reverts %d migration(s) from version %d and may drop data.", -arg, current)
+	case cmd == "goto" && uint(arg) < current:
+		return fmt.Sprintf("This reverts migrations from version %d down to %d and may drop data.", current, arg)
+	case cmd == "force":
+		return fmt.Sprintf("This records version %d as applied and clean without running any migration.", arg)
+	}
+	return ""
+}
+
+// confirm asks for an explicit "yes". Without a terminal to ask on it
+// refuses, so scripts have to pass -yes.
+func confirm(in *os.File, out io.Writer, prompt string) bool {
+	if fi, err := in.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		fmt.Fprintln(out, prompt, "Pass -yes to run it non-interactively.")
+		return false
+	}
+
+	fmt.Fprint(out, prompt, " Type 'yes' to continue: ")
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	return strings.TrimSpace(answer) == "yes"
+}
+
+func printVersion(version uint, dirty bool) {
+	switch {
+	case version == 0:
+		fmt.Println("version: none")
+	case dirty:
+		fmt.Printf("version: %d (dirty)\n", version)
+	default:
+		fmt.Printf("version: %d\n", version)
+	}
+}
+
+func printStatus(m *migrator.Migrator) int {
+	st, err := m.Status()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	printVersion(st.Version, st.Dirty)
+	fmt.Printf("latest:  %d\n\n", st.Latest)
+	for _, mig := range st.Migrations {
+		state := "pending"
+		switch {
+		case st.Dirty && mig.Version == st.Version:
+			state = "dirty"
+		case mig.Version <= st.Version:
+			state = "applied"
+		}
+		fmt.Printf("%4d  %-8s %s\n", mig.Version, state, mig.Name)
+	}
+
+	if st.Dirty {
+		fmt.Fprintln(os.Stderr, "\nDIRTY:", &migrator.DirtyError{Version: st.Version})
+		return 1
+	}
+	return 0
+}
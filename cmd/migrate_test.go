@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMigrateArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantCmd string
+		wantArg int
+		wantErr string
+	}{
+		{name: "up", args: []string{"up"}, wantCmd: "up"},
+		{name: "down", args: []string{"down"}, wantCmd: "down"},
+		{name: "version", args: []string{"version"}, wantCmd: "version"},
+		{name: "status", args: []string{"status"}, wantCmd: "status"},
+		{name: "steps forward", args: []string{"steps", "2"}, wantCmd: "steps", wantArg: 2},
+		{name: "steps back", args: []string{"steps", "-1"}, wantCmd: "steps", wantArg: -1},
+		{name: "goto", args: []string{"goto", "7"}, wantCmd: "goto", wantArg: 7},
+		{name: "goto zero", args: []string{"goto", "0"}, wantCmd: "goto"},
+		{name: "force", args: []string{"force", "3"}, wantCmd: "force", wantArg: 3},
+		{name: "force nil version", args: []string{"force", "-1"}, wantCmd: "force", wantArg: -1},
+
+		{name: "no command", args: nil, wantErr: "missing command"},
+		{name: "unknown command", args: []string{"redo"}, wantErr: "unknown command"},
+		{name: "extra argument", args: []string{"up", "1"}, wantErr: "takes 0 argument"},
+		{name: "missing argument", args: []string{"goto"}, wantErr: "takes 1 argument"},
+		{name: "not a number", args: []string{"steps", "two"}, wantErr: "not a number"},
+		{name: "zero steps", args: []string{"steps", "0"}, wantErr: "must not be 0"},
+		{name: "negative version", args: []string{"goto", "-2"}, wantErr: "needs a version"},
+		{name: "force below nil version", args: []string{"force", "-2"}, wantErr: "version or -1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, arg, err := parseMigrateArgs(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseMigrateArgs(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMigrateArgs(%q): %v", tt.args, err)
+			}
+			if cmd != tt.wantCmd || arg != tt.wantArg {
+				t.Errorf("parseMigrateArgs(%q) = %s %d, want %s %d", tt.args, cmd, arg, tt.wantCmd, tt.wantArg)
+			}
+		})
+	}
+}
+
+func TestDestructive(t *testing.T) {
+	tests := []struct {
+		name    string
+		cmd     string
+		arg     int
+		current uint
+		want    bool
+	}{
+		{name: "up", cmd: "up", current: 5},
+		{name: "down", cmd: "down", current: 5, want: true},
+		{name: "steps forward", cmd: "steps", arg: 2, current: 5},
+		{name: "steps back", cmd: "steps", arg: -1, current: 5, want: true},
+		{name: "goto later version", cmd: "goto", arg: 7, current: 5},
+		{name: "goto current version", cmd: "goto", arg: 5, current: 5},
+		{name: "goto earlier version", cmd: "goto", arg: 3, current: 5, want: true},
+		{name: "force", cmd: "force", arg: 5, current: 5, want: true},
+		{name: "status", cmd: "status", current: 5},
+		{name: "version", cmd: "version", current: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := destructive(tt.cmd, tt.arg, tt.current)
+			if got := prompt != ""; got != tt.want {
+				t.Errorf("destructive(%s, %d, %d) = %q, want destructive %v", tt.cmd, tt.arg, tt.current, prompt, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Command schemacheck fails when an event schema change would break
+// existing consumers. Every version of an event must be readable by
+// consumers of the previous version, every version needs its JSON Schema,
+// .proto and .avsc, and with -baseline (e.g. a checkout of the main
+// branch) already published schema files of all three formats must stay
+// compatible with what was released.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"ice/internal/event"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// subdirectories of the event directory holding each format
+const (
+	jsonDir  = "schema"
+	protoDir = "proto"
+	avroDir  = "avro"
+)
+
+// formats holds the schemas of every format found under one directory
+type formats struct {
+	json  map[event.SchemaKey]any
+	proto map[event.SchemaKey]protoreflect.MessageDescriptor
+	avro  map[event.SchemaKey]avro.Schema
+}
+
+func main() {
+	dir := flag.String("dir", "internal/event", "directory holding the schema, proto and avro subdirectories")
+	baseline := flag.String("baseline", "", "event directory holding the previously released schemas")
+	flag.Parse()
+
+	current, err := load(*dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var problems []string
+	report := func(from, to event.SchemaKey, issues []string) {
+		for _, issue := range issues {
+			problems = append(problems, fmt.Sprintf("%s -> %s: %s", from, to, issue))
+		}
+	}
+
+	keys := make([]event.SchemaKey, 0, len(current.json))
+	for key := range current.json {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type < keys[j].Type
+		}
+		return keys[i].Version < keys[j].Version
+	})
+
+	// consecutive versions of the same event
+	for i := 1; i < len(keys); i++ {
+		prev, next := keys[i-1], keys[i]
+		if prev.Type != next.Type {
+			continue
+		}
+		if next.Version != prev.Version+1 {
+			problems = append(problems, fmt.Sprintf("%s: version gap after %s", next, prev))
+		}
+		report(prev, next, event.CheckCompatibility(current.json[prev], current.json[next]))
+	}
+
+	// the binary codecs need a message for every contract
+	for _, key := range keys {
+		if _, ok := current.proto[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: no .proto message", key))
+		}
+		if _, ok := current.avro[key]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: no .avsc schema", key))
+		}
+	}
+
+	// released schemas must neither disappear nor break; binary versions
+	// are picked by the schema_version header, so they are only compared
+	// with their own release
+	if *baseline != "" {
+		released, err := load(*baseline)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		problems = append(problems, compareReleased("JSON", released.json, current.json, event.CheckCompatibility)...)
+		problems = append(problems, compareReleased("protobuf", released.proto, current.proto, event.CheckProtoCompatibility)...)
+		problems = append(problems, compareReleased("avro", released.avro, current.avro, event.CheckAvroCompatibility)...)
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		fmt.Fprintln(os.Stderr, "breaking event schema changes:")
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, "  "+p)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d event schemas are compatible\n", len(current.json))
+}
+
+// load reads the schemas of dir; a format whose subdirectory is missing,
+// as in releases that predate it, has none
+func load(dir string) (formats, error) {
+	var f formats
+	var err error
+
+	if f.json, err = event.LoadSchemas(os.DirFS(filepath.Join(dir, jsonDir))); err != nil {
+		return f, err
+	}
+
+	if f.proto, err = event.LoadProtoMessages(os.DirFS(filepath.Join(dir, protoDir))); err != nil {
+		return f, err
+	}
+	f.avro, err = event.LoadAvroSchemas(os.DirFS(filepath.Join(dir, avroDir)))
+	return f, err
+}
+
+// compareReleased checks every released schema of one format against its
+// current file
+func compareReleased[S any](format string, released, current map[event.SchemaKey]S, check func(old, new S) []string) []string {
+	var problems []string
+	for key, old := range released {
+		next, ok := current[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: released %s schema was removed", key, format))
+			continue
+		}
+		for _, issue := range check(old, next) {
+			problems = append(problems, fmt.Sprintf("%s -> %s: %s", key, key, issue))
+		}
+	}
+	return problems
+}
@@ -1,15 +1,21 @@
 package config
 
 import (
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	MySQL MySQLConfig
-	Redis RedisConfig
-	HTTP  HTTPConfig
+	MySQL    MySQLConfig
+	Redis    RedisConfig
+	HTTP     HTTPConfig
+	Health   HealthConfig
+	Outbox   OutboxConfig
+	Reminder ReminderConfig
+	Trash    TrashConfig
 }
 
 type MySQLConfig struct {
@@ -18,16 +24,134 @@ type MySQLConfig struct {
 	User     string
 	Password string
 	Database string
+	// Replicas lists read replica addresses (host:port) sharing the
+	// primary's credentials and database name
+	Replicas              []string
+	ReplicaHealthInterval time.Duration
+	// MigrationsPath replaces the migrations embedded in the binary with
+	// the ones in this directory
+	MigrationsPath string
+	// MigrateOnStart applies pending migrations before serving; instances
+	// starting together wait up to MigrateLockTimeout for the one migrating
+	MigrateOnStart     bool
+	MigrateLockTimeout time.Duration
+	// SchemaCheck is strict (refuse to start while the schema is behind or
+	// dirty), readiness (start but report not ready) or off
+	SchemaCheck string
 }
 
 type RedisConfig struct {
-	Addr     string
+	// Mode is one of standalone, sentinel or cluster
+	Mode string
+	// Addr is the standalone server address
+	Addr string
+	// Addrs are the cluster seed nodes
+	Addrs []string
+	// MasterName and SentinelAddrs locate the master in sentinel mode
+	MasterName       string
+	SentinelAddrs    []string
+	SentinelUsername string
+	SentinelPassword string
+
+	Username string
 	Password string
 	DB       int
+
+	TLS                   bool
+	TLSServerName         string
+	TLSInsecureSkipVerify bool
+
+	PoolSize     int
+	MinIdleConns int
+	PoolTimeout  time.Duration
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// PublishDedupTTL guards stream appends with a SET NX per message ID
+	// for this long, zero disables the guard
+	PublishDedupTTL time.Duration
 }
 
 type HTTPConfig struct {
 	Port string
+	// BatchMaxOperations caps the operations of a POST /todos:batch
+	BatchMaxOperations int
+	// ImportMaxRows caps the records of a POST /todos/import
+	ImportMaxRows int
+	// BatchMaxBytes and ImportMaxBytes cap the request bodies of those
+	// routes, zero leaves them unbounded
+	BatchMaxBytes  int64
+	ImportMaxBytes int64
+	// PublicURL is the base URL clients reach the service at, used in the
+	// calendar feed URLs instead of the Host of the request
+	PublicURL string
+	// TrustedProxies are the CIDRs of proxies whose X-Forwarded-For gives
+	// the client IP; without any the peer address is the client IP
+	TrustedProxies []string
+	// GatewayToken is sent by the gateway in X-Gateway-Token; only then is
+	// X-Actor trusted, otherwise it is recorded as claimed
+	GatewayToken string
+}
+
+type OutboxConfig struct {
+	// BatchSize is the number of messages fetched per query
+	BatchSize int
+	// Polling backs off from PollMinInterval to PollMaxInterval while the
+	// outbox is empty
+	PollMinInterval time.Duration
+	PollMaxInterval time.Duration
+	// NotifyChannel enables redis pub/sub wake-ups across instances
+	NotifyChannel string
+	// Workers publishing a topic concurrently, TopicWorkers overrides it
+	// per topic; ordering is kept per partition key
+	Workers      int
+	TopicWorkers map[string]int
+	// MaxAttempts before a message is dead-lettered as failed; retries
+	// back off exponentially up to MaxBackoff and block later messages of
+	// the same aggregate
+	MaxAttempts int
+	MaxBackoff  time.Duration
+	// Codec encodes events (json, protobuf or avro), TopicCodecs overrides
+	// it per topic
+	Codec       string
+	TopicCodecs map[string]string
+}
+
+type ReminderConfig struct {
+	Enabled bool
+	// Interval between scans for due reminders
+	Interval time.Duration
+	// Windows are the lead times before the due date a todo is reminded
+	// at, e.g. 24h and 1h
+	Windows []time.Duration
+	// OverdueLookback limits overdue reminders to todos that passed their
+	// due date within this window, zero disables overdue reminders
+	OverdueLookback time.Duration
+	// BatchSize is the number of todos handled per window and scan
+	BatchSize int
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted todos stay restorable before they are
+	// purged, zero keeps them forever
+	Retention time.Duration
+	// PurgeInterval is the time between purges
+	PurgeInterval time.Duration
+	// PurgeBatchSize is the number of todos removed per batch; a purge
+	// goes on while batches come back full
+	PurgeBatchSize int
+}
+
+type HealthConfig struct {
+	// CheckTimeout bounds each dependency check
+	CheckTimeout time.Duration
+	// CacheTTL is how long a check result is reused across probes
+	CacheTTL time.Duration
+	// Outbox thresholds degrade readiness once exceeded, zero disables
+	OutboxMaxLag     time.Duration
+	OutboxMaxPending int64
+	OutboxMaxFailed  int64
 }
 
 func Load() *Config {
@@ -43,12 +167,69 @@ func Load() *Config {
 	v.SetDefault("mysql.user", "root")
 	v.SetDefault("mysql.password", "root")
 	v.SetDefault("mysql.database", "todos")
+	v.SetDefault("mysql.replicas", "")
+	v.SetDefault("mysql.replica_health_interval", "5s")
+	v.SetDefault("mysql.migrations_path", "")
+	v.SetDefault("mysql.migrate_on_start", false)
+	v.SetDefault("mysql.migrate_lock_timeout", "1m")
+	v.SetDefault("mysql.schema_check", "readiness")
 	// Redis defaults
+	v.SetDefault("redis.mode", "standalone")
 	v.SetDefault("redis.addr", "localhost:6379")
+	v.SetDefault("redis.addrs", "")
+	v.SetDefault("redis.master_name", "")
+	v.SetDefault("redis.sentinel_addrs", "")
+	v.SetDefault("redis.sentinel_username", "")
+	v.SetDefault("redis.sentinel_password", "")
+	v.SetDefault("redis.username", "")
 	v.SetDefault("redis.password", "")
 	v.SetDefault("redis.db", 0)
+	v.SetDefault("redis.tls", false)
+	v.SetDefault("redis.tls_server_name", "")
+	v.SetDefault("redis.tls_insecure_skip_verify", false)
+	v.SetDefault("redis.pool_size", 0) // 0 lets go-redis pick 10 per CPU
+	v.SetDefault("redis.min_idle_conns", 0)
+	v.SetDefault("redis.pool_timeout", "4s")
+	v.SetDefault("redis.dial_timeout", "5s")
+	v.SetDefault("redis.read_timeout", "3s")
+	v.SetDefault("redis.write_timeout", "3s")
+	v.SetDefault("redis.publish_dedup_ttl", "0")
 	// HTTP default
 	v.SetDefault("http.port", "8080")
+	v.SetDefault("http.batch_max_operations", 500)
+	v.SetDefault("http.import_max_rows", 10000)
+	v.SetDefault("http.batch_max_bytes", 4<<20)
+	v.SetDefault("http.import_max_bytes", 32<<20)
+	v.SetDefault("http.public_url", "http://localhost:8080")
+	v.SetDefault("http.trusted_proxies", "")
+	v.SetDefault("http.gateway_token", "")
+	// Outbox defaults
+	v.SetDefault("outbox.batch_size", 30)
+	v.SetDefault("outbox.poll_min_interval", "100ms")
+	v.SetDefault("outbox.poll_max_interval", "5s")
+	v.SetDefault("outbox.notify_channel", "")
+	v.SetDefault("outbox.workers", 1)
+	v.SetDefault("outbox.topic_workers", "")
+	v.SetDefault("outbox.max_attempts", 5)
+	v.SetDefault("outbox.max_backoff", "5m")
+	v.SetDefault("outbox.codec", "json")
+	v.SetDefault("outbox.topic_codecs", "")
+	// Reminder defaults
+	v.SetDefault("reminder.enabled", true)
+	v.SetDefault("reminder.interval", "1m")
+	v.SetDefault("reminder.windows", "24h,1h")
+	v.SetDefault("reminder.overdue_lookback", "24h")
+	v.SetDefault("reminder.batch_size", 100)
+	// Trash defaults
+	v.SetDefault("trash.retention", "720h")
+	v.SetDefault("trash.purge_interval", "1h")
+	v.SetDefault("trash.purge_batch_size", 100)
+	// Health defaults
+	v.SetDefault("health.check_timeout", "2s")
+	v.SetDefault("health.cache_ttl", "1s")
+	v.SetDefault("health.outbox_max_lag", "1m")
+	v.SetDefault("health.outbox_max_pending", 1000)
+	v.SetDefault("health.outbox_max_failed", 0)
 
 	conf := &Config{
 		MySQL: MySQLConfig{
@@ -57,15 +238,132 @@ func Load() *Config {
 			User:     v.GetString("mysql.user"),
 			Password: v.GetString("mysql.password"),
 			Database: v.GetString("mysql.database"),
+
+			Replicas:              splitList(v.GetString("mysql.replicas")),
+			ReplicaHealthInterval: v.GetDuration("mysql.replica_health_interval"),
+			MigrationsPath:        v.GetString("mysql.migrations_path"),
+			MigrateOnStart:        v.GetBool("mysql.migrate_on_start"),
+			MigrateLockTimeout:    v.GetDuration("mysql.migrate_lock_timeout"),
+			SchemaCheck:           v.GetString("mysql.schema_check"),
 		},
 		Redis: RedisConfig{
-			Addr:     v.GetString("redis.addr"),
+			Mode:             v.GetString("redis.mode"),
+			Addr:             v.GetString("redis.addr"),
+			Addrs:            splitList(v.GetString("redis.addrs")),
+			MasterName:       v.GetString("redis.master_name"),
+			SentinelAddrs:    splitList(v.GetString("redis.sentinel_addrs")),
+			SentinelUsername: v.GetString("redis.sentinel_username"),
+			SentinelPassword: v.GetString("redis.sentinel_password"),
+
+			Username: v.GetString("redis.username"),
 			Password: v.GetString("redis.password"),
 			DB:       v.GetInt("redis.db"),
+
+			TLS:                   v.GetBool("redis.tls"),
+			TLSServerName:         v.GetString("redis.tls_server_name"),
+			TLSInsecureSkipVerify: v.GetBool("redis.tls_insecure_skip_verify"),
+
+			PoolSize:     v.GetInt("redis.pool_size"),
+			MinIdleConns: v.GetInt("redis.min_idle_conns"),
+			PoolTimeout:  v.GetDuration("redis.pool_timeout"),
+			DialTimeout:  v.GetDuration("redis.dial_timeout"),
+			ReadTimeout:  v.GetDuration("redis.read_timeout"),
+			WriteTimeout: v.GetDuration("redis.write_timeout"),
+
+			PublishDedupTTL: v.GetDuration("redis.publish_dedup_ttl"),
 		},
 		HTTP: HTTPConfig{
-			Port: v.GetString("http.port"),
+			Port:               v.GetString("http.port"),
+			BatchMaxOperations: v.GetInt("http.batch_max_operations"),
+			ImportMaxRows:      v.GetInt("http.import_max_rows"),
+			BatchMaxBytes:      v.GetInt64("http.batch_max_bytes"),
+			ImportMaxBytes:     v.GetInt64("http.import_max_bytes"),
+			PublicURL:          v.GetString("http.public_url"),
+			TrustedProxies:     splitList(v.GetString("http.trusted_proxies")),
+			GatewayToken:       v.GetString("http.gateway_token"),
+		},
+		Outbox: OutboxConfig{
+			BatchSize:       v.GetInt("outbox.batch_size"),
+			PollMinInterval: v.GetDuration("outbox.poll_min_interval"),
+			PollMaxInterval: v.GetDuration("outbox.poll_max_interval"),
+			NotifyChannel:   v.GetString("outbox.notify_channel"),
+			Workers:         v.GetInt("outbox.workers"),
+			TopicWorkers:    splitIntMap(v.GetString("outbox.topic_workers")),
+			MaxAttempts:     v.GetInt("outbox.max_attempts"),
+			MaxBackoff:      v.GetDuration("outbox.max_backoff"),
+			Codec:           v.GetString("outbox.codec"),
+			TopicCodecs:     splitMap(v.GetString("outbox.topic_codecs")),
+		},
+		Reminder: ReminderConfig{
+			Enabled:         v.GetBool("reminder.enabled"),
+			Interval:        v.GetDuration("reminder.interval"),
+			Windows:         splitDurations(v.GetString("reminder.windows")),
+			OverdueLookback: v.GetDuration("reminder.overdue_lookback"),
+			BatchSize:       v.GetInt("reminder.batch_size"),
+		},
+		Trash: TrashConfig{
+			Retention:      v.GetDuration("trash.retention"),
+			PurgeInterval:  v.GetDuration("trash.purge_interval"),
+			PurgeBatchSize: v.GetInt("trash.purge_batch_size"),
+		},
+		Health: HealthConfig{
+			CheckTimeout:     v.GetDuration("health.check_timeout"),
+			CacheTTL:         v.GetDuration("health.cache_ttl"),
+			OutboxMaxLag:     v.GetDuration("health.outbox_max_lag"),
+			OutboxMaxPending: v.GetInt64("health.outbox_max_pending"),
+			OutboxMaxFailed:  v.GetInt64("health.outbox_max_failed"),
 		},
 	}
 	return conf
 }
+
+// splitList parses a comma separated env value, dropping empty entries
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// splitMap parses "key=value" pairs from a comma separated env value,
+// dropping malformed entries
+func splitMap(s string) map[string]string {
+	m := make(map[string]string)
+	for _, item := range splitList(s) {
+		key, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return m
+}
+
+// splitIntMap is splitMap for integer values
+func splitIntMap(s string) map[string]int {
+	m := make(map[string]int)
+	for key, value := range splitMap(s) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		m[key] = n
+	}
+	return m
+}
+
+// splitDurations is splitList for durations, dropping malformed entries
+func splitDurations(s string) []time.Duration {
+	var list []time.Duration
+	for _, item := range splitList(s) {
+		d, err := time.ParseDuration(item)
+		if err != nil {
+			continue
+		}
+		list = append(list, d)
+	}
+	return list
+}
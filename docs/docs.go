@@ -23,6 +23,160 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/outbox/status": {
+            "get": {
+                "description": "Pending and failed counts, age of the oldest pending message and last publish time; status is degraded beyond the configured thresholds",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Outbox status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/outbox.StatusResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Verbose readiness report, kept for existing monitors",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Succeeds as long as the process can serve requests; it does not depend on MySQL or Redis",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "List every check with its latency",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Fails when MySQL is unreachable; Redis and outbox lag only degrade the status since the outbox buffers events",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "List every check with its latency",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
+        "/startupz": {
+            "get": {
+                "description": "Fails until MySQL is reachable and the schema is migrated to the version the binary expects",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Startup probe",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "List every check with its latency",
+                        "name": "verbose",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        },
         "/todo": {
             "post": {
                 "description": "Create a new todo item and publish it to Redis Stream",
@@ -45,6 +199,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/todo.CreateTodoRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to the todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -68,9 +234,983 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/todo/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Get a todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to the todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.TodoResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Cached copy is current"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the description, due date and recurrence rule of a todo item; If-Match must carry the ETag the client last read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Update a todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Todo update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/todo.UpdateTodoRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being updated, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to the todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.TodoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move a todo item to the trash, from where it can be restored until it is purged; If-Match must carry the ETag the client last read",
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Delete a todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being deleted, or *",
+                        "name": "If-Match",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "428": {
+                        "description": "Precondition Required",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/{id}/complete": {
+            "post": {
+                "description": "Complete a todo item; for a recurring todo the next occurrence is created and returned as next. If-Match is optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Complete a todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to the todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the version being completed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.CompleteTodoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/{id}/history": {
+            "get": {
+                "description": "List every change made to a todo item, oldest first, with who made it and what changed. The history outlives the todo, purged todos included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Get the change history of a todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of entries to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to UTC",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/audit.HistoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/{id}/restore": {
+            "post": {
+                "description": "Restore a todo item from the trash as it was when deleted. If-Match is optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Restore a deleted todo item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Todo ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the deleted version, as listed in the trash",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to the todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.TodoResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/calendar.ics": {
+            "get": {
+                "description": "iCalendar feed of all todo items, trashed ones left out, for calendar apps to subscribe to. Todos have no owner, so every token reads the same global feed; the owner only names who the token was issued to. Each todo keeps its id as UID, so updates replace the entry rather than duplicate it. Timed todos are written in their own zone with a VTIMEZONE, all-day ones as dates, and recurring ones with their RRULE.\nThe token comes from POST /todos/calendar/token. By default todos are VEVENTs, which every calendar app shows; component=vtodo writes VTODOs with their completion state for task apps.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Subscribe to todos as a calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Secret feed token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "vevent",
+                            "vtodo"
+                        ],
+                        "type": "string",
+                        "default": "vevent",
+                        "description": "Component todos are written as",
+                        "name": "component",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/calendar/token": {
+            "post": {
+                "description": "Issue a secret token for the calendar feed to the actor named by X-Actor and return the feed URL, based on HTTP_PUBLIC_URL, to subscribe to. The request must come through the gateway with X-Gateway-Token, as the token grants read access to every todo. An actor has one token at a time; issuing a new one revokes the old URL. The token is only shown in this response.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Issue a calendar feed token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner of the token",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the gateway vouching for X-Actor",
+                        "name": "X-Gateway-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Revoke the calendar feed token of the actor named by X-Actor, vouched for by the gateway with X-Gateway-Token; its feed URL stops working at once.",
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Revoke a calendar feed token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner of the token",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret of the gateway vouching for X-Actor",
+                        "name": "X-Gateway-Token",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/export": {
+            "get": {
+                "description": "Stream all todo items, trashed ones left out, as CSV, a JSON array, NDJSON or an iCalendar file of VEVENTs. All-day due dates are written as plain dates in CSV, and CSV cells a spreadsheet would run as a formula are prefixed with an apostrophe. The output can be imported again.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "text/calendar"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Export todo items",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "ndjson",
+                            "ics"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to write times in, defaults to each todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to write times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/exchange.Record"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/import": {
+            "post": {
+                "description": "Create todo items from CSV, a JSON array, NDJSON or an iCalendar file, in the format of the export. From iCalendar every VEVENT and VTODO becomes a todo, due at its DUE or DTSTART, with its UID as externalId and its SUMMARY as description. The format is taken from the format parameter, else from the Content-Type. The whole input is read before anything is written: when it cannot be parsed, holds more than HTTP_IMPORT_MAX_ROWS records or is larger than HTTP_IMPORT_MAX_BYTES nothing is imported.\nInvalid records are reported and skipped. A record without externalId takes its exported id as one. Records whose externalId is already taken, by a todo or an earlier record of the file, are not created again. Records are committed 500 at a time; when the import fails midway the report says what was created, and running it again only adds the rest of the records with an external ID.",
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "application/x-ndjson",
+                    "text/calendar"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Import todo items",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "json",
+                            "ndjson",
+                            "ics"
+                        ],
+                        "type": "string",
+                        "description": "Input format, defaults to the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Validate and report without creating anything",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Todo items to import",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/exchange.Record"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/exchange.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/exchange.ImportResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/exchange.ImportResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/trash": {
+            "get": {
+                "description": "List the todo items in the trash, most recently deleted first. They are purged once the retention period has passed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "List deleted todo items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size, at most 200",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Number of items to skip",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to each todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.TrashResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos:batch": {
+            "post": {
+                "description": "Run up to HTTP_BATCH_MAX_OPERATIONS operations, in a body of at most HTTP_BATCH_MAX_BYTES, in a single transaction and report the outcome of each with the status its own endpoint would have answered. Operations are validated one by one; in best-effort mode failed operations are skipped, with atomic set any failure rolls the whole batch back and the others report 424.\nAnswers 200 when every operation succeeded, 207 when some failed in best-effort mode and 422 when an atomic batch was rolled back.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Create, update and delete todo items in bulk",
+                "parameters": [
+                    {
+                        "description": "Batch of operations",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/todo.BatchRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in, defaults to each todo's zone",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone to render times in when tz is not given",
+                        "name": "X-Time-Zone",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/todo.BatchResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/todo.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/todo.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.AppError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Action": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "complete",
+                "delete",
+                "restore",
+                "purge"
+            ],
+            "x-enum-varnames": [
+                "ActionCreate",
+                "ActionUpdate",
+                "ActionComplete",
+                "ActionDelete",
+                "ActionRestore",
+                "ActionPurge"
+            ]
+        },
+        "audit.EntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/audit.Action"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "changes": {
+                    "type": "object"
+                },
+                "clientIp": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-01T06:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "3f2b0c9e7d1a4e58"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "audit.HistoryResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.EntryResponse"
+                    }
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "todoId": {
+                    "type": "string",
+                    "example": "4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"
+                }
+            }
+        },
+        "calendar.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-01T06:00:00Z"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "token": {
+                    "description": "Token is only ever shown once, when issued",
+                    "type": "string",
+                    "example": "q0mT2dYQ6j7m0f0Yy3bW4xY1n1cQy8cYJ3ZtGx6kq0s"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "http://localhost:8080/todos/calendar.ics?token=q0mT2dYQ6j7m0f0Yy3bW4xY1n1cQy8cYJ3ZtGx6kq0s"
+                }
+            }
+        },
         "errors.AppError": {
             "type": "object",
             "properties": {
@@ -82,6 +1222,303 @@ const docTemplate = `{
                 }
             }
         },
+        "exchange.ImportDuplicate": {
+            "type": "object",
+            "properties": {
+                "externalId": {
+                    "type": "string",
+                    "example": "legacy-7"
+                },
+                "id": {
+                    "description": "ID of the todo holding the external ID",
+                    "type": "string",
+                    "example": "4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "exchange.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "dryRun": {
+                    "description": "DryRun reports what would have been imported, nothing was written",
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/exchange.ImportDuplicate"
+                    }
+                },
+                "error": {
+                    "description": "Error is set when the import stopped before its end",
+                    "type": "string",
+                    "example": "record 3: unexpected EOF"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/exchange.ImportRowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "skipped": {
+                    "description": "Skipped counts the records whose external ID is already taken",
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "exchange.ImportRowError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "description is required"
+                },
+                "externalId": {
+                    "type": "string",
+                    "example": "legacy-42"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "exchange.Record": {
+            "type": "object",
+            "required": [
+                "description",
+                "dueDate"
+            ],
+            "properties": {
+                "allDay": {
+                    "type": "boolean"
+                },
+                "completed": {
+                    "type": "boolean"
+                },
+                "completedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "dueDate": {
+                    "type": "string"
+                },
+                "externalId": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "id": {
+                    "type": "string"
+                },
+                "recurrence": {
+                    "type": "string"
+                },
+                "timeZone": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "checkedAt": {
+                    "type": "string"
+                },
+                "critical": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "outbox.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "lastPublishedAt": {
+                    "type": "string",
+                    "example": "2025-01-01T06:00:00Z"
+                },
+                "oldestPendingSeconds": {
+                    "type": "number",
+                    "example": 1.5
+                },
+                "pending": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "problems": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "todo.BatchOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "example": "update"
+                },
+                "todo": {
+                    "$ref": "#/definitions/todo.CreateTodoRequest"
+                },
+                "version": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 3
+                }
+            }
+        },
+        "todo.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "description": "Atomic rolls the whole batch back when an operation fails, instead\nof skipping the failed operations",
+                    "type": "boolean",
+                    "example": false
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/todo.BatchOperation"
+                    }
+                }
+            }
+        },
+        "todo.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/todo.BatchResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "todo.BatchResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "todo was modified concurrently"
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "todoItem": {
+                    "$ref": "#/definitions/todo.TodoItem"
+                }
+            }
+        },
+        "todo.CompleteTodoResponse": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "description": "Next is the todo created for the next occurrence of a recurring todo",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/todo.TodoItem"
+                        }
+                    ]
+                },
+                "todoItem": {
+                    "$ref": "#/definitions/todo.TodoItem"
+                }
+            }
+        },
         "todo.CreateTodoRequest": {
             "type": "object",
             "required": [
@@ -89,6 +1526,11 @@ const docTemplate = `{
                 "dueDate"
             ],
             "properties": {
+                "allDay": {
+                    "description": "AllDay makes the calendar date of DueDate, as written, the due date",
+                    "type": "boolean",
+                    "example": false
+                },
                 "description": {
                     "type": "string",
                     "minLength": 1,
@@ -97,6 +1539,16 @@ const docTemplate = `{
                 "dueDate": {
                     "type": "string",
                     "example": "2025-01-01T06:00:00Z"
+                },
+                "recurrence": {
+                    "description": "Recurrence is an iCalendar RRULE subset: FREQ=DAILY|WEEKLY|MONTHLY\nwith optional INTERVAL, BYDAY and UNTIL or COUNT",
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=MO,WE"
+                },
+                "timeZone": {
+                    "description": "TimeZone is the IANA zone the todo is planned in, used for all-day\ndates, recurrence and as the default zone of responses",
+                    "type": "string",
+                    "example": "Europe/Berlin"
                 }
             }
         },
@@ -111,17 +1563,121 @@ const docTemplate = `{
         "todo.TodoItem": {
             "type": "object",
             "properties": {
+                "allDay": {
+                    "description": "Due on a date rather than at a time",
+                    "type": "boolean"
+                },
+                "completed": {
+                    "description": "Completion state",
+                    "type": "boolean"
+                },
+                "completedAt": {
+                    "description": "Completion time, nil while open",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "Creation time",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "description": "Time the todo was moved to the trash, nil while live",
+                    "type": "string"
+                },
                 "description": {
                     "description": "Description",
                     "type": "string"
                 },
                 "dueDate": {
-                    "description": "Due date",
+                    "description": "Due date, UTC once stored",
+                    "type": "string"
+                },
+                "externalID": {
+                    "description": "ID in the tool the todo was imported from, empty if none",
                     "type": "string"
                 },
                 "id": {
                     "description": "UUID",
                     "type": "string"
+                },
+                "occurrence": {
+                    "description": "Position in its recurring series, starting at 1",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "RRULE, empty for a one-off todo",
+                    "type": "string"
+                },
+                "timeZone": {
+                    "description": "IANA zone the todo is planned in, empty for UTC",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "Last write time",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Incremented on every write, for optimistic locking",
+                    "type": "integer"
+                }
+            }
+        },
+        "todo.TodoResponse": {
+            "type": "object",
+            "properties": {
+                "todoItem": {
+                    "$ref": "#/definitions/todo.TodoItem"
+                }
+            }
+        },
+        "todo.TrashResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "offset": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "todoItems": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/todo.TodoItem"
+                    }
+                }
+            }
+        },
+        "todo.UpdateTodoRequest": {
+            "type": "object",
+            "required": [
+                "description",
+                "dueDate"
+            ],
+            "properties": {
+                "allDay": {
+                    "description": "AllDay makes the calendar date of DueDate, as written, the due date",
+                    "type": "boolean",
+                    "example": false
+                },
+                "description": {
+                    "type": "string",
+                    "minLength": 1,
+                    "example": "test task"
+                },
+                "dueDate": {
+                    "type": "string",
+                    "example": "2025-01-01T06:00:00Z"
+                },
+                "recurrence": {
+                    "description": "Recurrence replaces the rule, empty makes the todo a one-off",
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=MO,WE"
+                },
+                "timeZone": {
+                    "description": "TimeZone is the IANA zone the todo is planned in, used for all-day\ndates, recurrence and as the default zone of responses",
+                    "type": "string",
+                    "example": "Europe/Berlin"
                 }
             }
         }
@@ -0,0 +1,36 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+)
+
+type txKey struct{}
+
+type readYourWritesKey struct{}
+
+// WithReadYourWrites marks ctx so that reads are served by the primary,
+// e.g. when a handler reads back a row it has just written and cannot
+// tolerate replication lag
+func WithReadYourWrites(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readYourWritesKey{}, true)
+}
+
+func readYourWrites(ctx context.Context) bool {
+	v, _ := ctx.Value(readYourWritesKey{}).(bool)
+	return v
+}
+
+// txState is the transaction bound to a context by WithTx
+type txState struct {
+	tx          *sql.Tx
+	afterCommit []func()
+}
+
+func txFrom(ctx context.Context) (*sql.Tx, bool) {
+	st, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return st.tx, true
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"ice/pkg/logger"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Lock acquires the named MySQL advisory lock, waiting up to wait for it.
+// The lock lives on the session that took it, so a connection is pinned
+// until release is called; if the instance dies the server drops it along
+// with the connection. ok is false when another session holds the lock.
+func (m *MySQL) Lock(ctx context.Context, name string, wait time.Duration) (release func(), ok bool, err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to pin connection for lock %q: %w", name, err)
+	}
+
+	var got *int64
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", name, seconds).Scan(&got); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to acquire lock %q: %w", name, err)
+	}
+	if got == nil || *got != 1 {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release = func() {
+		// released on a fresh context so a cancelled caller still frees it
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		if _, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", name); err != nil {
+			logger.Get().Warn("failed to release mysql lock", zap.String("lock", name), zap.Error(err))
+			// discard the session instead of returning it to the pool
+			// with the lock still held
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
@@ -1,37 +1,219 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"ice/config"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ice/pkg/logger"
 
 	_ "github.com/go-sql-driver/mysql"
+	"go.uber.org/zap"
 )
 
+// Executor is the subset of *sql.DB and *sql.Tx used by repositories
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type MySQL struct {
-	db *sql.DB
+	db       *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// replica is a read-only connection pool that is ejected from the
+// round-robin while its health check fails
+type replica struct {
+	addr    string
+	db      *sql.DB
+	healthy atomic.Bool
 }
 
 func NewMySQL(cfg config.MySQLConfig) (*MySQL, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
-	db, err := sql.Open("mysql", dsn)
+	db, err := open(cfg, cfg.Host+":"+cfg.Port)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
+		return nil, err
 	}
 	if err := db.Ping(); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to ping mysql: %w", err)
 	}
-	return &MySQL{db: db}, nil
+
+	m := &MySQL{db: db, stop: make(chan struct{})}
+	for _, addr := range cfg.Replicas {
+		rdb, err := open(cfg, addr)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		r := &replica{addr: addr, db: rdb}
+		// an unreachable replica must not prevent startup, it is
+		// simply kept out of rotation until it answers a ping
+		r.healthy.Store(rdb.Ping() == nil)
+		m.replicas = append(m.replicas, r)
+	}
+
+	if len(m.replicas) > 0 {
+		interval := cfg.ReplicaHealthInterval
+		if interval <= 0 {
+			interval = 5 * time.Second
+		}
+		m.wg.Add(1)
+		go m.monitorReplicas(interval)
+	}
+	return m, nil
 }
 
+func open(cfg config.MySQLConfig, addr string) (*sql.DB, error) {
+	// times are exchanged in UTC both ways: the driver converts parameters
+	// to and parses results from UTC, and the session time zone makes
+	// NOW() and CURRENT_TIMESTAMP agree with it
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?parseTime=true&loc=UTC&time_zone=%s",
+		cfg.User, cfg.Password, addr, cfg.Database, url.QueryEscape("'+00:00'"))
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mysql connection: %w", err)
+	}
+	return db, nil
+}
+
+// DB returns the primary connection pool
 func (m *MySQL) DB() *sql.DB {
 	return m.db
 }
 
+// Writer returns the executor for statements that must run on the primary:
+// the transaction bound to ctx if any, otherwise the primary pool
+func (m *MySQL) Writer(ctx context.Context) Executor {
+	if tx, ok := txFrom(ctx); ok {
+		return tx
+	}
+	return m.db
+}
+
+// Reader returns the executor for read-only queries. Inside a transaction
+// or when ctx asks to read its own writes the primary is used, otherwise
+// the next healthy replica in round-robin order. It falls back to the
+// primary when no replica is configured or healthy.
+func (m *MySQL) Reader(ctx context.Context) Executor {
+	if tx, ok := txFrom(ctx); ok {
+		return tx
+	}
+	if readYourWrites(ctx) || len(m.replicas) == 0 {
+		return m.db
+	}
+
+	n := uint64(len(m.replicas))
+	start := m.next.Add(1)
+	for i := uint64(0); i < n; i++ {
+		r := m.replicas[(start+i)%n]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return m.db
+}
+
+// WithTx runs fn inside a transaction on the primary. Repositories called
+// with the ctx passed to fn join the transaction through Writer and Reader.
+// Nested calls reuse the outer transaction.
+func (m *MySQL) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFrom(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	st := &txState{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, st)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logger.Get().Error("failed to rollback transaction", zap.Error(rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, hook := range st.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// AfterCommit runs fn once the transaction bound to ctx has committed; it
+// is dropped on rollback. Outside a transaction fn runs immediately.
+func (m *MySQL) AfterCommit(ctx context.Context, fn func()) {
+	if st, ok := ctx.Value(txKey{}).(*txState); ok {
+		st.afterCommit = append(st.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+func (m *MySQL) monitorReplicas(interval time.Duration) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			for _, r := range m.replicas {
+				m.checkReplica(r, interval)
+			}
+		}
+	}
+}
+
+func (m *MySQL) checkReplica(r *replica, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := r.db.PingContext(ctx)
+	healthy := err == nil
+	if r.healthy.Swap(healthy) == healthy {
+		return
+	}
+
+	if healthy {
+		logger.Get().Info("mysql replica back in rotation", zap.String("replica", r.addr))
+	} else {
+		logger.Get().Warn("mysql replica ejected", zap.String("replica", r.addr), zap.Error(err))
+	}
+}
+
 func (m *MySQL) Close() error {
+	if m.stop != nil {
+		close(m.stop)
+		m.wg.Wait()
+		m.stop = nil
+	}
+
+	for _, r := range m.replicas {
+		r.db.Close()
+	}
+
 	if m.db != nil {
 		return m.db.Close()
 	}
 	return nil
 }
-
@@ -0,0 +1,72 @@
+package redis
+
+import (
+	"crypto/tls"
+	"fmt"
+	"ice/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Deployment modes accepted in config.RedisConfig.Mode
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+// newUniversalClient builds the client matching the configured deployment
+// mode; the rest of the service only depends on redis.UniversalClient
+func newUniversalClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	opts := &redis.UniversalOptions{
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		DB:               cfg.DB,
+		SentinelUsername: cfg.SentinelUsername,
+		SentinelPassword: cfg.SentinelPassword,
+		PoolSize:         cfg.PoolSize,
+		MinIdleConns:     cfg.MinIdleConns,
+		PoolTimeout:      cfg.PoolTimeout,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+	}
+
+	if cfg.TLS {
+		opts.TLSConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         cfg.TLSServerName,
+			InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+		}
+	}
+
+	switch cfg.Mode {
+	case "", ModeStandalone:
+		opts.Addrs = []string{cfg.Addr}
+		return redis.NewClient(opts.Simple()), nil
+
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis sentinel mode requires a master name")
+		}
+		if len(cfg.SentinelAddrs) == 0 {
+			return nil, fmt.Errorf("redis sentinel mode requires at least one sentinel address")
+		}
+		opts.MasterName = cfg.MasterName
+		opts.Addrs = cfg.SentinelAddrs
+		return redis.NewFailoverClient(opts.Failover()), nil
+
+	case ModeCluster:
+		if len(cfg.Addrs) == 0 {
+			return nil, fmt.Errorf("redis cluster mode requires at least one node address")
+		}
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("redis cluster mode does not support selecting db %d", cfg.DB)
+		}
+		opts.Addrs = cfg.Addrs
+		return redis.NewClusterClient(opts.Cluster()), nil
+
+	default:
+		return nil, fmt.Errorf("unknown redis mode %q", cfg.Mode)
+	}
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MessageID returns the producer assigned ID of a stream entry, false for
+// entries published without one
+func MessageID(msg redis.XMessage) (string, bool) {
+	id, ok := msg.Values[FieldMessageID].(string)
+	return id, ok && id != ""
+}
+
+// Deduplicator lets stream consumers skip entries they already handled,
+// e.g. when the outbox republished a message whose bookkeeping failed
+type Deduplicator struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewDeduplicator scopes the seen set to consumer, usually the consumer
+// group name, and remembers IDs for ttl
+func NewDeduplicator(client redis.UniversalClient, consumer string, ttl time.Duration) *Deduplicator {
+	return &Deduplicator{
+		client: client,
+		prefix: "dedup:" + consumer + ":",
+		ttl:    ttl,
+	}
+}
+
+// FirstSeen atomically records messageID and reports whether this is the
+// first time it was seen
+func (d *Deduplicator) FirstSeen(ctx context.Context, messageID string) (bool, error) {
+	return d.client.SetNX(ctx, d.prefix+messageID, 1, d.ttl).Result()
+}
+
+// Forget drops messageID, so a redelivery is handled again after the
+// consumer failed to process it
+func (d *Deduplicator) Forget(ctx context.Context, messageID string) error {
+	return d.client.Del(ctx, d.prefix+messageID).Err()
+}
+
+// Handle runs fn for msg unless its message ID was already handled. Entries
+// without a message ID are always handled. When fn fails the ID is
+// forgotten so the entry can be retried.
+func (d *Deduplicator) Handle(ctx context.Context, msg redis.XMessage, fn func(ctx context.Context, msg redis.XMessage) error) error {
+	id, ok := MessageID(msg)
+	if !ok {
+		return fn(ctx, msg)
+	}
+
+	first, err := d.FirstSeen(ctx, id)
+	if err != nil || !first {
+		return err
+	}
+
+	if err := fn(ctx, msg); err != nil {
+		if fErr := d.Forget(ctx, id); fErr != nil {
+			return fErr
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+	"ice/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// OutboxNotifier broadcasts outbox wake-ups over redis pub/sub so every
+// instance drains right after a commit instead of at its next poll
+type OutboxNotifier struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+func NewOutboxNotifier(client redis.UniversalClient, channel string) *OutboxNotifier {
+	return &OutboxNotifier{client: client, channel: channel}
+}
+
+func (n *OutboxNotifier) Notify(ctx context.Context) error {
+	return n.client.Publish(ctx, n.channel, "").Err()
+}
+
+// Subscribe coalesces nudges: a burst received while the consumer is busy
+// results in a single pending value
+func (n *OutboxNotifier) Subscribe(ctx context.Context) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	sub := n.client.Subscribe(ctx, n.channel)
+
+	go func() {
+		defer sub.Close()
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-msgs:
+				if !ok {
+					logger.Get().Warn("outbox notification subscription closed", zap.String("channel", n.channel))
+					return
+				}
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return out
+}
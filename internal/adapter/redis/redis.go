@@ -2,63 +2,167 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"ice/config"
+	"ice/internal/port"
+	"sort"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// Stream entry fields
+const (
+	FieldMessageID = "message_id"
+	FieldPayload   = "payload"
+)
+
 type RedisStreamClient struct {
-	client *redis.Client
+	client redis.UniversalClient
 	stream string
+	// dedupTTL enables the publish-side SET NX guard when positive
+	dedupTTL time.Duration
 }
 
 func NewRedisStreamClient(cfg config.RedisConfig) (*RedisStreamClient, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:     cfg.Addr,
-		Password: cfg.Password,
-		DB:       cfg.DB,
-	})
+	client, err := newUniversalClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	// Test connection
 	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
 	return &RedisStreamClient{
-		client: client,
-		stream: "todos",
+		client:   client,
+		stream:   "todos",
+		dedupTTL: cfg.PublishDedupTTL,
 	}, nil
 }
 
-func (r *RedisStreamClient) Publish(ctx context.Context, stream string, data interface{}) error {
-	// تبدیل data به JSON
-	payload, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
+// publishTimeout keeps a slow redis from blocking the outbox processor
+const publishTimeout = 2 * time.Second
+
+// Publish appends msg exactly as encoded by the producer
+func (r *RedisStreamClient) Publish(ctx context.Context, msg port.StreamMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	return r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: msg.Stream,
+		Values: entryFields(msg),
+	}).Err()
+}
 
-	// ارسال به Redis Stream
-	args := &redis.XAddArgs{
-		Stream: stream,
-		Values: map[string]interface{}{
-			"payload": string(payload),
-		},
-		MaxLen: 0, // می‌توانید محدودیت طول Stream را تنظیم کنید
+// PublishBatch appends every message in a single MULTI/EXEC round-trip,
+// so no other client's entries interleave with the batch. Redis does not
+// roll a transaction back: a command failing inside EXEC, or a timeout
+// after EXEC was sent, can leave part of the batch appended, and the
+// caller's retry of the whole batch appends those entries again with
+// their message ID. In cluster mode all messages must target the same
+// stream.
+//
+// With the dedup guard enabled a message whose ID was published within
+// the guard TTL is skipped, which makes republishing after a lost
+// MarkSent harmless.
+func (r *RedisStreamClient) PublishBatch(ctx context.Context, msgs []port.StreamMessage) error {
+	if len(msgs) == 0 {
+		return nil
 	}
 
-	// با timeout کوتاه برای جلوگیری از بلاک شدن
-	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
 	defer cancel()
 
-	_, err = r.client.XAdd(ctx, args).Result()
+	if r.dedupTTL > 0 {
+		return r.publishDeduplicated(ctx, msgs)
+	}
+
+	pipe := r.client.TxPipeline()
+	for _, msg := range msgs {
+		pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: msg.Stream,
+			Values: entryFields(msg),
+		})
+	}
+	_, err := pipe.Exec(ctx)
 	return err
 }
 
-func (r *RedisStreamClient) Client() *redis.Client {
+// dedupScript appends each entry only if its guard key could be set.
+// KEYS: stream, then one guard key per message.
+// ARGV: guard TTL in ms, then per message the number of field/value pairs
+// followed by the pairs themselves.
+var dedupScript = redis.NewScript(`
+local appended = 0
+local pos = 2
+for i = 2, #KEYS do
+	local n = tonumber(ARGV[pos]) * 2
+	local fields = {}
+	for j = 1, n do
+		fields[j] = ARGV[pos + j]
+	end
+	pos = pos + n + 1
+	if redis.call('SET', KEYS[i], 1, 'NX', 'PX', ARGV[1]) then
+		redis.call('XADD', KEYS[1], '*', unpack(fields))
+		appended = appended + 1
+	end
+end
+return appended
+`)
+
+func (r *RedisStreamClient) publishDeduplicated(ctx context.Context, msgs []port.StreamMessage) error {
+	stream := msgs[0].Stream
+	keys := make([]string, 0, len(msgs)+1)
+	args := make([]any, 0, len(msgs)*8+1)
+	keys = append(keys, stream)
+	args = append(args, r.dedupTTL.Milliseconds())
+
+	for _, msg := range msgs {
+		if msg.Stream != stream {
+			return fmt.Errorf("deduplicated batch mixes streams %q and %q", stream, msg.Stream)
+		}
+		fields := entryFields(msg)
+		keys = append(keys, publishGuardKey(stream, msg.ID))
+		args = append(args, len(fields)/2)
+		args = append(args, fields...)
+	}
+
+	return dedupScript.Run(ctx, r.client, keys, args...).Err()
+}
+
+// publishGuardKey hash-tags the stream name so guards live in the same
+// cluster slot as their stream, as required by the script
+func publishGuardKey(stream, id string) string {
+	return "{" + stream + "}:published:" + id
+}
+
+// entryFields lays out a stream entry as field/value pairs: the message
+// ID, the headers in key order and the untouched payload bytes last.
+// Headers cannot shadow the reserved fields.
+func entryFields(msg port.StreamMessage) []any {
+	keys := make([]string, 0, len(msg.Headers))
+	for k := range msg.Headers {
+		if k != FieldMessageID && k != FieldPayload {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	fields := make([]any, 0, len(keys)*2+4)
+	if msg.ID != "" {
+		fields = append(fields, FieldMessageID, msg.ID)
+	}
+	for _, k := range keys {
+		fields = append(fields, k, msg.Headers[k])
+	}
+	return append(fields, FieldPayload, msg.Payload)
+}
+
+func (r *RedisStreamClient) Client() redis.UniversalClient {
 	return r.client
 }
 
@@ -0,0 +1,44 @@
+package audit
+
+import "time"
+
+type EntryResponse struct {
+	ID        int64     `json:"id" example:"42"`
+	Action    Action    `json:"action" example:"update"`
+	Version   int       `json:"version" example:"3"`
+	Actor     string    `json:"actor" example:"alice"`
+	RequestID string    `json:"requestId,omitempty" example:"3f2b0c9e7d1a4e58"`
+	ClientIP  string    `json:"clientIp,omitempty" example:"203.0.113.7"`
+	Changes   Changes   `json:"changes" swaggertype:"object"`
+	CreatedAt time.Time `json:"createdAt" example:"2025-01-01T06:00:00Z"`
+}
+
+type HistoryResponse struct {
+	TodoID  string          `json:"todoId" example:"4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"`
+	Entries []EntryResponse `json:"entries"`
+	Limit   int             `json:"limit" example:"50"`
+	Offset  int             `json:"offset" example:"0"`
+}
+
+// NewHistoryResponse renders entries with their times in loc
+func NewHistoryResponse(todoID string, entries []Entry, limit, offset int, loc *time.Location) HistoryResponse {
+	resp := HistoryResponse{
+		TodoID:  todoID,
+		Entries: make([]EntryResponse, len(entries)),
+		Limit:   limit,
+		Offset:  offset,
+	}
+	for i, e := range entries {
+		resp.Entries[i] = EntryResponse{
+			ID:        e.ID,
+			Action:    e.Action,
+			Version:   e.Version,
+			Actor:     e.Actor,
+			RequestID: e.RequestID,
+			ClientIP:  e.ClientIP,
+			Changes:   e.Changes,
+			CreatedAt: e.CreatedAt.In(loc),
+		}
+	}
+	return resp
+}
@@ -0,0 +1,182 @@
+// Package audit records who changed a todo, when and how
+package audit
+
+import (
+	"context"
+	"ice/internal/todo"
+	"net"
+	"time"
+)
+
+type Action string
+
+const (
+	ActionCreate   Action = "create"
+	ActionUpdate   Action = "update"
+	ActionComplete Action = "complete"
+	ActionDelete   Action = "delete"
+	ActionRestore  Action = "restore"
+	ActionPurge    Action = "purge"
+)
+
+// ActorAnonymous is recorded when a request names no actor
+const ActorAnonymous = "anonymous"
+
+// ClaimedPrefix marks an actor named by a request the gateway did not
+// vouch for, e.g. "claimed:alice"
+const ClaimedPrefix = "claimed:"
+
+// Lengths of the actor and request_id columns, in characters
+const (
+	maxActor     = 255
+	maxRequestID = 128
+)
+
+// Entry is a row of the append-only todo audit log
+type Entry struct {
+	ID     int64
+	TodoID string
+	Action Action
+	// Version is the todo version the mutation produced, or the last one
+	// for a purge
+	Version   int
+	Actor     string
+	RequestID string
+	ClientIP  string
+	Changes   Changes
+	CreatedAt time.Time
+}
+
+// Change is the value of a field before and after a mutation, nil when
+// the field was unset
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// Changes maps the fields a mutation changed to their change
+type Changes map[string]Change
+
+// Source describes who caused a mutation
+type Source struct {
+	Actor string
+	// Verified is set when the actor was vouched for by the gateway;
+	// otherwise a non-empty Actor is only claimed
+	Verified  bool
+	RequestID string
+	ClientIP  string
+}
+
+type sourceKey struct{}
+
+// WithSource binds the source of the mutations made with ctx
+func WithSource(ctx context.Context, s Source) context.Context {
+	return context.WithValue(ctx, sourceKey{}, s)
+}
+
+// SourceFrom returns the source bound to ctx, with ActorAnonymous when no
+// actor was given
+func SourceFrom(ctx context.Context) Source {
+	s, _ := ctx.Value(sourceKey{}).(Source)
+	if s.Actor == "" {
+		s.Actor = ActorAnonymous
+	}
+	return s
+}
+
+// recordedActor is the actor as written to the log, prefixed with
+// ClaimedPrefix unless verified and cut to the length of its column
+func (s Source) recordedActor() string {
+	actor := s.Actor
+	if !s.Verified && actor != ActorAnonymous {
+		actor = ClaimedPrefix + actor
+	}
+	return truncate(actor, maxActor)
+}
+
+// truncate cuts s to n characters
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// clientIP is ip in canonical form, empty when it is not an address
+func clientIP(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	return parsed.String()
+}
+
+// NewEntry records a mutation from before to after, either being nil for a
+// todo that did not exist before or does not exist after
+func NewEntry(ctx context.Context, action Action, before, after *todo.TodoItem) Entry {
+	src := SourceFrom(ctx)
+	e := Entry{
+		Action:    action,
+		Actor:     src.recordedActor(),
+		RequestID: truncate(src.RequestID, maxRequestID),
+		ClientIP:  clientIP(src.ClientIP),
+		Changes:   Diff(before, after),
+	}
+	switch {
+	case after != nil:
+		e.TodoID, e.Version = after.ID, after.Version
+	case before != nil:
+		e.TodoID, e.Version = before.ID, before.Version
+	}
+	return e
+}
+
+// Diff lists the fields that differ between before and after. Versions
+// and timestamps maintained by the repository are left out, except
+// completion and deletion times.
+func Diff(before, after *todo.TodoItem) Changes {
+	from, to := snapshot(before), snapshot(after)
+	changes := make(Changes)
+	for field, value := range to {
+		if from[field] != value {
+			changes[field] = Change{From: from[field], To: value}
+		}
+	}
+	for field, value := range from {
+		if _, ok := to[field]; !ok {
+			changes[field] = Change{From: value}
+		}
+	}
+	return changes
+}
+
+// snapshot holds the audited fields of item as comparable JSON values,
+// unset ones left out
+func snapshot(item *todo.TodoItem) map[string]any {
+	if item == nil {
+		return nil
+	}
+	s := map[string]any{
+		"description": item.Description,
+		"dueDate":     item.DueDate.UTC().Format(time.RFC3339),
+		"allDay":      item.AllDay,
+		"completed":   item.Completed,
+		"occurrence":  item.Occurrence,
+	}
+	if item.ExternalID != "" {
+		s["externalId"] = item.ExternalID
+	}
+	if item.TimeZone != "" {
+		s["timeZone"] = item.TimeZone
+	}
+	if item.Recurrence != "" {
+		s["recurrence"] = item.Recurrence
+	}
+	if item.CompletedAt != nil {
+		s["completedAt"] = item.CompletedAt.UTC().Format(time.RFC3339)
+	}
+	if item.DeletedAt != nil {
+		s["deletedAt"] = item.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	return s
+}
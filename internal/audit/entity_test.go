@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"ice/internal/todo"
+)
+
+func TestDiff(t *testing.T) {
+	due := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	done := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	base := func(change func(*todo.TodoItem)) *todo.TodoItem {
+		item := &todo.TodoItem{ID: "a", Description: "buy milk", DueDate: due, Occurrence: 1, Version: 1}
+		if change != nil {
+			change(item)
+		}
+		return item
+	}
+
+	tests := []struct {
+		name          string
+		before, after *todo.TodoItem
+		want          Changes
+	}{
+		{
+			name:  "create",
+			after: base(nil),
+			want: Changes{
+				"description": {To: "buy milk"},
+				"dueDate":     {To: "2025-03-01T09:00:00Z"},
+				"allDay":      {To: false},
+				"completed":   {To: false},
+				"occurrence":  {To: 1},
+			},
+		},
+		{
+			name:   "purge",
+			before: base(func(i *todo.TodoItem) { i.Recurrence = "FREQ=DAILY" }),
+			want: Changes{
+				"description": {From: "buy milk"},
+				"dueDate":     {From: "2025-03-01T09:00:00Z"},
+				"allDay":      {From: false},
+				"completed":   {From: false},
+				"occurrence":  {From: 1},
+				"recurrence":  {From: "FREQ=DAILY"},
+			},
+		},
+		{
+			name:   "no change",
+			before: base(nil),
+			after:  base(nil),
+			want:   Changes{},
+		},
+		{
+			name:   "version and timestamps left out",
+			before: base(nil),
+			after: base(func(i *todo.TodoItem) {
+				i.Version, i.UpdatedAt, i.CreatedAt = 2, done, done
+			}),
+			want: Changes{},
+		},
+		{
+			name:   "same instant in another zone",
+			before: base(nil),
+			after: base(func(i *todo.TodoItem) {
+				i.DueDate = due.In(time.FixedZone("", 3600))
+			}),
+			want: Changes{},
+		},
+		{
+			name:   "description",
+			before: base(nil),
+			after:  base(func(i *todo.TodoItem) { i.Description = "buy oat milk" }),
+			want:   Changes{"description": {From: "buy milk", To: "buy oat milk"}},
+		},
+		{
+			name:   "complete",
+			before: base(nil),
+			after: base(func(i *todo.TodoItem) {
+				i.Completed, i.CompletedAt = true, &done
+			}),
+			want: Changes{
+				"completed":   {From: false, To: true},
+				"completedAt": {To: "2025-03-01T10:00:00Z"},
+			},
+		},
+		{
+			name:   "delete",
+			before: base(nil),
+			after:  base(func(i *todo.TodoItem) { i.DeletedAt = &done }),
+			want:   Changes{"deletedAt": {To: "2025-03-01T10:00:00Z"}},
+		},
+		{
+			name:   "restore",
+			before: base(func(i *todo.TodoItem) { i.DeletedAt = &done }),
+			after:  base(nil),
+			want:   Changes{"deletedAt": {From: "2025-03-01T10:00:00Z"}},
+		},
+		{
+			name:   "optional fields set and cleared",
+			before: base(func(i *todo.TodoItem) { i.TimeZone = "Europe/Berlin" }),
+			after: base(func(i *todo.TodoItem) {
+				i.ExternalID, i.Recurrence = "ext-1", "FREQ=WEEKLY"
+			}),
+			want: Changes{
+				"timeZone":   {From: "Europe/Berlin"},
+				"externalId": {To: "ext-1"},
+				"recurrence": {To: "FREQ=WEEKLY"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEntryActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		source *Source
+		want   string
+	}{
+		{name: "no source", want: ActorAnonymous},
+		{name: "no actor", source: &Source{Verified: true}, want: ActorAnonymous},
+		{name: "unverified", source: &Source{Actor: "alice"}, want: "claimed:alice"},
+		{name: "verified", source: &Source{Actor: "alice", Verified: true}, want: "alice"},
+		{name: "cut to the column", source: &Source{Actor: strings.Repeat("é", 300)}, want: ClaimedPrefix + strings.Repeat("é", maxActor-len(ClaimedPrefix))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.source != nil {
+				ctx = WithSource(ctx, *tt.source)
+			}
+			if got := NewEntry(ctx, ActionCreate, nil, &todo.TodoItem{ID: "a"}).Actor; got != tt.want {
+				t.Errorf("Actor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEntrySource(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        Source
+		wantIP        string
+		wantRequestID string
+	}{
+		{name: "ipv4", source: Source{ClientIP: "203.0.113.7", RequestID: "r1"}, wantIP: "203.0.113.7", wantRequestID: "r1"},
+		{name: "ipv6 in canonical form", source: Source{ClientIP: "2001:DB8:0:0::1"}, wantIP: "2001:db8::1"},
+		{name: "not an address", source: Source{ClientIP: strings.Repeat("1", 60)}, wantIP: ""},
+		{name: "zone left out", source: Source{ClientIP: "fe80::1%eth0"}, wantIP: ""},
+		{name: "request id cut to the column", source: Source{RequestID: strings.Repeat("r", 200)}, wantRequestID: strings.Repeat("r", maxRequestID)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEntry(WithSource(context.Background(), tt.source), ActionCreate, nil, &todo.TodoItem{ID: "a"})
+			if e.ClientIP != tt.wantIP {
+				t.Errorf("ClientIP = %q, want %q", e.ClientIP, tt.wantIP)
+			}
+			if e.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %q, want %q", e.RequestID, tt.wantRequestID)
+			}
+		})
+	}
+}
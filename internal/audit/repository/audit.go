@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"ice/internal/audit"
+	"strings"
+	"time"
+)
+
+// insertChunk bounds the rows per INSERT, keeping statements well below
+// the placeholder limit and max_allowed_packet
+const insertChunk = 200
+
+// Append writes entry to the audit log, in the transaction bound to ctx
+// if any
+func (r *Repository) Append(ctx context.Context, entry *audit.Entry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`INSERT INTO todo_audit (todo_id, action, version, actor, request_id, client_ip, changes, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.TodoID, entry.Action, entry.Version, entry.Actor, entry.RequestID, entry.ClientIP, changes, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	entry.ID, err = res.LastInsertId()
+	entry.CreatedAt = now
+	return err
+}
+
+// AppendBatch writes entries with multi-row inserts, as Append does one,
+// but only fills their creation time: the ids of a multi-row insert are
+// not consecutive under innodb_autoinc_lock_mode=2, so they are left unset
+func (r *Repository) AppendBatch(ctx context.Context, entries []*audit.Entry) error {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	for start := 0; start < len(entries); start += insertChunk {
+		chunk := entries[start:min(start+insertChunk, len(entries))]
+
+		values := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*8)
+		for i, entry := range chunk {
+			changes, err := json.Marshal(entry.Changes)
+			if err != nil {
+				return err
+			}
+			values[i] = "(?, ?, ?, ?, ?, ?, ?, ?)"
+			args = append(args, entry.TodoID, entry.Action, entry.Version, entry.Actor, entry.RequestID, entry.ClientIP, changes, now)
+		}
+
+		if _, err := r.mysql.Writer(ctx).ExecContext(ctx,
+			`INSERT INTO todo_audit (todo_id, action, version, actor, request_id, client_ip, changes, created_at)
+			 VALUES `+strings.Join(values, ", "),
+			args...,
+		); err != nil {
+			return err
+		}
+		for _, entry := range chunk {
+			entry.CreatedAt = now
+		}
+	}
+	return nil
+}
+
+// List returns a page of the history of a todo, oldest first. It reads
+// from the primary so a change shows up right after it was made.
+func (r *Repository) List(ctx context.Context, todoID string, limit, offset int) ([]audit.Entry, error) {
+	rows, err := r.mysql.Writer(ctx).QueryContext(ctx,
+		`SELECT id, todo_id, action, version, actor, request_id, client_ip, changes, created_at
+		 FROM todo_audit
+		 WHERE todo_id = ?
+		 ORDER BY id
+		 LIMIT ? OFFSET ?`,
+		todoID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []audit.Entry{}
+	for rows.Next() {
+		var (
+			e       audit.Entry
+			changes []byte
+		)
+		if err := rows.Scan(&e.ID, &e.TodoID, &e.Action, &e.Version, &e.Actor,
+			&e.RequestID, &e.ClientIP, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &e.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,11 @@
+package calendar
+
+import "time"
+
+type TokenResponse struct {
+	// Token is only ever shown once, when issued
+	Token     string    `json:"token" example:"q0mT2dYQ6j7m0f0Yy3bW4xY1n1cQy8cYJ3ZtGx6kq0s"`
+	URL       string    `json:"url" example:"http://localhost:8080/todos/calendar.ics?token=q0mT2dYQ6j7m0f0Yy3bW4xY1n1cQy8cYJ3ZtGx6kq0s"`
+	Owner     string    `json:"owner" example:"alice"`
+	CreatedAt time.Time `json:"createdAt" example:"2025-01-01T06:00:00Z"`
+}
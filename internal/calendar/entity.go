@@ -0,0 +1,38 @@
+package calendar
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"time"
+)
+
+var ErrTokenNotFound = errors.New("calendar token not found")
+
+// Token grants read access to the calendar feed through a secret in its
+// URL. Each owner has at most one; only the hash of the secret is stored.
+type Token struct {
+	Owner     string    // Actor the token was issued to
+	Hash      []byte    // SHA-256 of the secret
+	CreatedAt time.Time // Issue time
+}
+
+// secretBytes is the entropy of a secret
+const secretBytes = 32
+
+// NewToken issues a token to owner, returning it with its secret
+func NewToken(owner string, now time.Time) (*Token, string, error) {
+	b := make([]byte, secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	return &Token{Owner: owner, Hash: Hash(secret), CreatedAt: now}, secret, nil
+}
+
+// Hash is what a secret is stored and looked up as
+func Hash(secret string) []byte {
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
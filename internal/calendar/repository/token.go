@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"ice/internal/calendar"
+)
+
+// Save stores token, replacing the one its owner had
+func (r *Repository) Save(ctx context.Context, token *calendar.Token) error {
+	_, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`INSERT INTO calendar_tokens (owner, token_hash, created_at) VALUES (?, ?, ?)
+		 ON DUPLICATE KEY UPDATE token_hash = VALUES(token_hash), created_at = VALUES(created_at)`,
+		token.Owner, token.Hash, token.CreatedAt,
+	)
+	return err
+}
+
+// Delete removes the token of owner
+func (r *Repository) Delete(ctx context.Context, owner string) error {
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`DELETE FROM calendar_tokens WHERE owner = ?`, owner,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return calendar.ErrTokenNotFound
+	}
+	return nil
+}
+
+// FindByHash looks a token up by the hash of its secret. It reads the
+// primary so a revoked token stops working at once.
+func (r *Repository) FindByHash(ctx context.Context, hash []byte) (*calendar.Token, error) {
+	var token calendar.Token
+	err := r.mysql.Writer(ctx).QueryRowContext(ctx,
+		`SELECT owner, token_hash, created_at FROM calendar_tokens WHERE token_hash = ?`, hash,
+	).Scan(&token.Owner, &token.Hash, &token.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, calendar.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
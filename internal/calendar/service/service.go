@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"ice/internal/calendar"
+	"ice/internal/port"
+	"time"
+)
+
+type Service struct {
+	repo port.CalendarTokenRepository
+}
+
+func NewService(repo port.CalendarTokenRepository) *Service {
+	return &Service{repo: repo}
+}
+
+// IssueToken gives owner a new feed token, revoking the one it had. The
+// secret is returned once and cannot be recovered later.
+func (s *Service) IssueToken(ctx context.Context, owner string) (*calendar.Token, string, error) {
+	// DATETIME keeps whole seconds
+	token, secret, err := calendar.NewToken(owner, time.Now().UTC().Truncate(time.Second))
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.repo.Save(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+func (s *Service) RevokeToken(ctx context.Context, owner string) error {
+	return s.repo.Delete(ctx, owner)
+}
+
+// Authenticate returns the token a secret belongs to, or
+// calendar.ErrTokenNotFound
+func (s *Service) Authenticate(ctx context.Context, secret string) (*calendar.Token, error) {
+	if secret == "" {
+		return nil, calendar.ErrTokenNotFound
+	}
+	return s.repo.FindByHash(ctx, calendar.Hash(secret))
+}
@@ -0,0 +1,234 @@
+package event
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Codec names accepted in the outbox configuration
+const (
+	CodecJSON     = "json"
+	CodecProtobuf = "protobuf"
+	CodecAvro     = "avro"
+)
+
+// Content types recorded on outbox rows and published with each entry
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "application/avro"
+)
+
+//go:embed proto/*.proto avro/*.avsc
+var codecFS embed.FS
+
+// Codec turns an event into its wire format. Encode receives the JSON
+// form of the event, already validated against its JSON Schema.
+type Codec interface {
+	ContentType() string
+	Encode(e Event, jsonPayload []byte) ([]byte, error)
+}
+
+// NewCodec builds the codec registered under name, loading the local
+// .proto or .avsc files shipped with the binary
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case "", CodecJSON:
+		return jsonCodec{}, nil
+	case CodecProtobuf:
+		return newProtobufCodec()
+	case CodecAvro:
+		return newAvroCodec()
+	default:
+		return nil, fmt.Errorf("unknown event codec %q", name)
+	}
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) Encode(_ Event, jsonPayload []byte) ([]byte, error) {
+	return jsonPayload, nil
+}
+
+// protobufCodec maps the JSON form of an event onto the message defined in
+// proto/<type>.v<version>.proto, each file declaring a single message
+type protobufCodec struct {
+	messages map[SchemaKey]protoreflect.MessageDescriptor
+}
+
+func newProtobufCodec() (*protobufCodec, error) {
+	sub, _ := fs.Sub(codecFS, "proto")
+	messages, err := LoadProtoMessages(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &protobufCodec{messages: messages}, nil
+}
+
+// LoadProtoMessages compiles every .proto file in fsys, each declaring the
+// message of one event version
+func LoadProtoMessages(fsys fs.FS) (map[SchemaKey]protoreflect.MessageDescriptor, error) {
+	files, err := fs.Glob(fsys, "*.proto")
+	if err != nil {
+		return nil, err
+	}
+
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: func(path string) (io.ReadCloser, error) {
+				return fsys.Open(path)
+			},
+		}),
+	}
+
+	messages := make(map[SchemaKey]protoreflect.MessageDescriptor, len(files))
+	for _, file := range files {
+		key, err := parseSchemaKey(file, ".proto")
+		if err != nil {
+			return nil, err
+		}
+
+		compiled, err := compiler.Compile(context.Background(), file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile %s: %w", file, err)
+		}
+		msgs := compiled[0].Messages()
+		if msgs.Len() != 1 {
+			return nil, fmt.Errorf("%s must declare exactly one message, found %d", file, msgs.Len())
+		}
+		messages[key] = msgs.Get(0)
+	}
+	return messages, nil
+}
+
+func (c *protobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (c *protobufCodec) Encode(e Event, jsonPayload []byte) ([]byte, error) {
+	key := SchemaKey{Type: e.EventType(), Version: e.EventVersion()}
+	desc, ok := c.messages[key]
+	if !ok {
+		return nil, fmt.Errorf("no protobuf message defined for %s", key)
+	}
+
+	msg := dynamicpb.NewMessage(desc)
+	if err := protojson.Unmarshal(jsonPayload, msg); err != nil {
+		return nil, fmt.Errorf("failed to map %s onto %s: %w", key, desc.FullName(), err)
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// avroCodec encodes events with the record schema in
+// avro/<type>.v<version>.avsc as plain binary, without container header
+type avroCodec struct {
+	schemas map[SchemaKey]avro.Schema
+}
+
+func newAvroCodec() (*avroCodec, error) {
+	sub, _ := fs.Sub(codecFS, "avro")
+	schemas, err := LoadAvroSchemas(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &avroCodec{schemas: schemas}, nil
+}
+
+// LoadAvroSchemas parses every .avsc file in fsys. Each file gets its own
+// cache, so two releases of the same record can be loaded side by side.
+func LoadAvroSchemas(fsys fs.FS) (map[SchemaKey]avro.Schema, error) {
+	files, err := fs.Glob(fsys, "*.avsc")
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make(map[SchemaKey]avro.Schema, len(files))
+	for _, file := range files {
+		key, err := parseSchemaKey(file, ".avsc")
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		schema, err := avro.ParseWithCache(string(raw), "", &avro.SchemaCache{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		schemas[key] = schema
+	}
+	return schemas, nil
+}
+
+func (c *avroCodec) ContentType() string { return ContentTypeAvro }
+
+func (c *avroCodec) Encode(e Event, _ []byte) ([]byte, error) {
+	key := SchemaKey{Type: e.EventType(), Version: e.EventVersion()}
+	schema, ok := c.schemas[key]
+	if !ok {
+		return nil, fmt.Errorf("no avro schema defined for %s", key)
+	}
+	return avro.Marshal(schema, e)
+}
+
+// Encoder picks the codec configured for each topic
+type Encoder struct {
+	fallback Codec
+	topics   map[string]Codec
+}
+
+// NewEncoder uses the codec named fallback for every topic not listed in
+// topicCodecs
+func NewEncoder(fallback string, topicCodecs map[string]string) (*Encoder, error) {
+	cache := make(map[string]Codec)
+	load := func(name string) (Codec, error) {
+		if c, ok := cache[name]; ok {
+			return c, nil
+		}
+		c, err := NewCodec(name)
+		if err != nil {
+			return nil, err
+		}
+		cache[name] = c
+		return c, nil
+	}
+
+	def, err := load(fallback)
+	if err != nil {
+		return nil, err
+	}
+	enc := &Encoder{fallback: def, topics: make(map[string]Codec, len(topicCodecs))}
+	for topic, name := range topicCodecs {
+		c, err := load(name)
+		if err != nil {
+			return nil, fmt.Errorf("topic %s: %w", topic, err)
+		}
+		enc.topics[topic] = c
+	}
+	return enc, nil
+}
+
+// Encode returns e in the wire format of topic along with its content type
+func (enc *Encoder) Encode(topic string, e Event, jsonPayload []byte) ([]byte, string, error) {
+	c, ok := enc.topics[topic]
+	if !ok {
+		c = enc.fallback
+	}
+	payload, err := c.Encode(e, jsonPayload)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, c.ContentType(), nil
+}
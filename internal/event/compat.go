@@ -0,0 +1,157 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// CheckCompatibility lists the changes from old to new that would break a
+// consumer written against old: removed properties, properties that are
+// no longer required, changed types or formats and new enum values.
+// Adding optional properties is always allowed.
+func CheckCompatibility(old, new any) []string {
+	return compareSchema("", asObject(old), asObject(new))
+}
+
+func compareSchema(at string, old, new map[string]any) []string {
+	var problems []string
+	where := at
+	if where == "" {
+		where = "root"
+	}
+
+	if o, n := old["type"], new["type"]; o != nil && !reflect.DeepEqual(o, n) {
+		problems = append(problems, fmt.Sprintf("%s: type changed from %v to %v", where, o, n))
+	}
+	if o, n := old["format"], new["format"]; o != nil && !reflect.DeepEqual(o, n) {
+		problems = append(problems, fmt.Sprintf("%s: format changed from %v to %v", where, o, n))
+	}
+	if oldEnum, ok := old["enum"].([]any); ok {
+		for _, v := range asSlice(new["enum"]) {
+			if !contains(oldEnum, v) {
+				problems = append(problems, fmt.Sprintf("%s: enum value %v added", where, v))
+			}
+		}
+	}
+
+	newRequired := asSlice(new["required"])
+	for _, name := range asSlice(old["required"]) {
+		if !contains(newRequired, name) {
+			problems = append(problems, fmt.Sprintf("%s: property %v is no longer required", where, name))
+		}
+	}
+
+	oldProps, newProps := asObject(old["properties"]), asObject(new["properties"])
+	names := make([]string, 0, len(oldProps))
+	for name := range oldProps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := name
+		if at != "" {
+			child = at + "." + name
+		}
+		newProp, ok := newProps[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: property removed", child))
+			continue
+		}
+		problems = append(problems, compareSchema(child, asObject(oldProps[name]), asObject(newProp))...)
+	}
+
+	if oldItems, ok := old["items"]; ok {
+		problems = append(problems, compareSchema(where+"[]", asObject(oldItems), asObject(new["items"]))...)
+	}
+	return problems
+}
+
+func asObject(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func contains(list []any, v any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAvroCompatibility lists the changes from old to new, two releases
+// of the same event version, that would break its consumers. Payloads are
+// bare records decoded field by field, so the old fields must keep their
+// position and type; new fields may only be appended, with a default that
+// fills them when an entry written with old is resolved against new.
+func CheckAvroCompatibility(old, new avro.Schema) []string {
+	oldRecord, ok := old.(*avro.RecordSchema)
+	if !ok {
+		return []string{"root: not a record"}
+	}
+	newRecord, ok := new.(*avro.RecordSchema)
+	if !ok {
+		return []string{"root: no longer a record"}
+	}
+	oldFields, newFields := oldRecord.Fields(), newRecord.Fields()
+
+	var problems []string
+	for i, f := range oldFields {
+		if i >= len(newFields) || newFields[i].Name() != f.Name() {
+			problems = append(problems, fmt.Sprintf("%s: field removed or moved from position %d", f.Name(), i))
+			continue
+		}
+		if o, n := f.Type().String(), newFields[i].Type().String(); o != n {
+			problems = append(problems, fmt.Sprintf("%s: type changed from %s to %s", f.Name(), o, n))
+		}
+	}
+	for _, f := range newFields[min(len(oldFields), len(newFields)):] {
+		if !f.HasDefault() {
+			problems = append(problems, fmt.Sprintf("%s: added without a default", f.Name()))
+		}
+	}
+	return problems
+}
+
+// CheckProtoCompatibility lists the changes from old to new, two releases
+// of the same event version, that would break its consumers: a field
+// number that disappears or changes name, kind or cardinality. Fields with
+// new numbers are skipped by old consumers and read as zero values from
+// older entries, so they are always allowed.
+func CheckProtoCompatibility(old, new protoreflect.MessageDescriptor) []string {
+	var problems []string
+	oldFields, newFields := old.Fields(), new.Fields()
+	for i := 0; i < oldFields.Len(); i++ {
+		f := oldFields.Get(i)
+		n := newFields.ByNumber(f.Number())
+		if n == nil {
+			problems = append(problems, fmt.Sprintf("%s = %d: field removed", f.Name(), f.Number()))
+			continue
+		}
+		if n.Name() != f.Name() {
+			// the codec maps the JSON form by field name
+			problems = append(problems, fmt.Sprintf("%s = %d: renamed to %s", f.Name(), f.Number(), n.Name()))
+		}
+		if n.Kind() != f.Kind() || n.Cardinality() != f.Cardinality() {
+			problems = append(problems, fmt.Sprintf("%s = %d: changed from %s %s to %s %s",
+				f.Name(), f.Number(), f.Cardinality(), f.Kind(), n.Cardinality(), n.Kind()))
+			continue
+		}
+		if f.Kind() == protoreflect.MessageKind && n.Message().FullName() != f.Message().FullName() {
+			problems = append(problems, fmt.Sprintf("%s = %d: message changed from %s to %s",
+				f.Name(), f.Number(), f.Message().FullName(), n.Message().FullName()))
+		}
+	}
+	return problems
+}
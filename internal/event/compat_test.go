@@ -0,0 +1,129 @@
+package event
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const avroBase = `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"},
+  {"name": "dueDate", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+]}`
+
+func TestCheckAvroCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		new      string
+		problems int
+	}{
+		{name: "unchanged", new: avroBase},
+		{name: "field appended with default", new: `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"},
+  {"name": "dueDate", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+  {"name": "version", "type": "int", "default": 0}
+]}`},
+		{name: "field appended without default", new: `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"},
+  {"name": "dueDate", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+  {"name": "version", "type": "int"}
+]}`, problems: 1},
+		{name: "field inserted before others", new: `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"},
+  {"name": "version", "type": "int", "default": 0},
+  {"name": "dueDate", "type": {"type": "long", "logicalType": "timestamp-millis"}}
+]}`, problems: 2},
+		{name: "field removed", new: `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"}
+]}`, problems: 1},
+		{name: "logical type dropped", new: `{"type": "record", "name": "TodoUpdated", "fields": [
+  {"name": "id", "type": "string"},
+  {"name": "dueDate", "type": "long"}
+]}`, problems: 1},
+	}
+
+	old := loadAvro(t, avroBase)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := CheckAvroCompatibility(old, loadAvro(t, tt.new))
+			if len(problems) != tt.problems {
+				t.Errorf("got %d problems %q, want %d", len(problems), problems, tt.problems)
+			}
+		})
+	}
+}
+
+const protoBase = `syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message TodoUpdated {
+  string id = 1;
+  google.protobuf.Timestamp due_date = 2;
+}`
+
+func TestCheckProtoCompatibility(t *testing.T) {
+	tests := []struct {
+		name     string
+		new      string
+		problems int
+	}{
+		{name: "unchanged", new: protoBase},
+		{name: "field added", new: `syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message TodoUpdated {
+  string id = 1;
+  google.protobuf.Timestamp due_date = 2;
+  int32 version = 3;
+}`},
+		{name: "field removed", new: `syntax = "proto3";
+message TodoUpdated {
+  string id = 1;
+}`, problems: 1},
+		{name: "field renamed", new: `syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message TodoUpdated {
+  string todo_id = 1;
+  google.protobuf.Timestamp due_date = 2;
+}`, problems: 1},
+		{name: "kind changed", new: `syntax = "proto3";
+message TodoUpdated {
+  string id = 1;
+  int64 due_date = 2;
+}`, problems: 1},
+		{name: "made repeated", new: `syntax = "proto3";
+import "google/protobuf/timestamp.proto";
+message TodoUpdated {
+  repeated string id = 1;
+  google.protobuf.Timestamp due_date = 2;
+}`, problems: 1},
+	}
+
+	old := loadProto(t, protoBase)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := CheckProtoCompatibility(old, loadProto(t, tt.new))
+			if len(problems) != tt.problems {
+				t.Errorf("got %d problems %q, want %d", len(problems), problems, tt.problems)
+			}
+		})
+	}
+}
+
+func loadAvro(t *testing.T, schema string) avro.Schema {
+	t.Helper()
+	schemas, err := LoadAvroSchemas(fstest.MapFS{"todo_updated.v1.avsc": {Data: []byte(schema)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schemas[SchemaKey{Type: TypeTodoUpdated, Version: 1}]
+}
+
+func loadProto(t *testing.T, file string) protoreflect.MessageDescriptor {
+	t.Helper()
+	messages, err := LoadProtoMessages(fstest.MapFS{"todo_updated.v1.proto": {Data: []byte(file)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messages[SchemaKey{Type: TypeTodoUpdated, Version: 1}]
+}
@@ -0,0 +1,32 @@
+package event
+
+// TopicTodo is the stream todo events are published to
+const TopicTodo = "todo_stream"
+
+// Event types, each backed by versioned JSON Schema files in schema/
+const (
+	TypeTodoCreated   = "todo_created"
+	TypeTodoUpdated   = "todo_updated"
+	TypeTodoCompleted = "todo_completed"
+	TypeTodoDeleted   = "todo_deleted"
+	TypeTodoRestored  = "todo_restored"
+	TypeTodoPurged    = "todo_purged"
+	TypeTodoDueSoon   = "todo_due_soon"
+	TypeTodoOverdue   = "todo_overdue"
+)
+
+// Event is a versioned contract published through the outbox
+type Event interface {
+	EventType() string
+	EventVersion() int
+}
+
+// Meta is embedded in every event so consumers can pick the schema to
+// decode a payload with
+type Meta struct {
+	Type          string `json:"type" avro:"type"`
+	SchemaVersion int    `json:"schema_version" avro:"schema_version"`
+}
+
+func (m Meta) EventType() string { return m.Type }
+func (m Meta) EventVersion() int { return m.SchemaVersion }
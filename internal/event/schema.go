@@ -0,0 +1,120 @@
+package event
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+// SchemaFS holds the schema files shipped with the binary
+func SchemaFS() fs.FS {
+	sub, _ := fs.Sub(schemaFS, "schema")
+	return sub
+}
+
+// schemaFile matches <event type>.v<version>.<ext>
+var schemaFile = regexp.MustCompile(`^([a-z0-9_]+)\.v([0-9]+)(\.[a-z]+)$`)
+
+// SchemaKey identifies one version of an event contract
+type SchemaKey struct {
+	Type    string
+	Version int
+}
+
+func (k SchemaKey) String() string {
+	return fmt.Sprintf("%s.v%d", k.Type, k.Version)
+}
+
+func parseSchemaKey(file, ext string) (SchemaKey, error) {
+	m := schemaFile.FindStringSubmatch(path.Base(file))
+	if m == nil || m[3] != ext {
+		return SchemaKey{}, fmt.Errorf("schema file %s is not named <type>.v<version>%s", file, ext)
+	}
+	version, _ := strconv.Atoi(m[2])
+	return SchemaKey{Type: m[1], Version: version}, nil
+}
+
+// LoadSchemas decodes every schema file in fsys
+func LoadSchemas(fsys fs.FS) (map[SchemaKey]any, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[SchemaKey]any, len(files))
+	for _, file := range files {
+		key, err := parseSchemaKey(file, ".json")
+		if err != nil {
+			return nil, err
+		}
+
+		raw, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schema %s: %w", file, err)
+		}
+		docs[key] = doc
+	}
+	return docs, nil
+}
+
+// Registry validates event payloads against their JSON Schema
+type Registry struct {
+	schemas map[SchemaKey]*jsonschema.Schema
+}
+
+// NewRegistry compiles the schemas shipped with the binary
+func NewRegistry() (*Registry, error) {
+	docs, err := LoadSchemas(SchemaFS())
+	if err != nil {
+		return nil, err
+	}
+
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+
+	r := &Registry{schemas: make(map[SchemaKey]*jsonschema.Schema, len(docs))}
+	for key, doc := range docs {
+		url := "mem:///" + key.String() + ".json"
+		if err := c.AddResource(url, doc); err != nil {
+			return nil, fmt.Errorf("failed to add schema %s: %w", key, err)
+		}
+		sch, err := c.Compile(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile schema %s: %w", key, err)
+		}
+		r.schemas[key] = sch
+	}
+	return r, nil
+}
+
+// Validate checks an encoded payload against the schema of the given event
+// type and version
+func (r *Registry) Validate(eventType string, version int, payload []byte) error {
+	key := SchemaKey{Type: eventType, Version: version}
+	sch, ok := r.schemas[key]
+	if !ok {
+		return fmt.Errorf("no schema registered for %s", key)
+	}
+
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("payload is not valid json: %w", err)
+	}
+	if err := sch.Validate(inst); err != nil {
+		return fmt.Errorf("payload does not match schema %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package event
+
+import (
+	"ice/internal/todo"
+	"time"
+)
+
+// TodoCreated is published when a todo item is created
+type TodoCreated struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	Description string    `json:"description" avro:"description"`
+	DueDate     time.Time `json:"dueDate" avro:"dueDate"`
+	// TimeZone and AllDay tell consumers how to present DueDate, which is
+	// always UTC
+	TimeZone string `json:"timeZone,omitempty" avro:"timeZone"`
+	AllDay   bool   `json:"allDay,omitempty" avro:"allDay"`
+	// Recurrence is the RRULE of a recurring todo
+	Recurrence string `json:"recurrence,omitempty" avro:"recurrence"`
+	// Version is the todo version after the change, as sent in ETags
+	Version int `json:"version,omitempty" avro:"version"`
+}
+
+func NewTodoCreated(item *todo.TodoItem) TodoCreated {
+	return TodoCreated{
+		Meta:        Meta{Type: TypeTodoCreated, SchemaVersion: 1},
+		ID:          item.ID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		TimeZone:    item.TimeZone,
+		AllDay:      item.AllDay,
+		Recurrence:  item.Recurrence,
+		Version:     item.Version,
+	}
+}
+
+// TodoUpdated is published when a todo item is edited
+type TodoUpdated struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	Description string    `json:"description" avro:"description"`
+	DueDate     time.Time `json:"dueDate" avro:"dueDate"`
+	TimeZone    string    `json:"timeZone,omitempty" avro:"timeZone"`
+	AllDay      bool      `json:"allDay,omitempty" avro:"allDay"`
+	Recurrence  string    `json:"recurrence,omitempty" avro:"recurrence"`
+	Version     int       `json:"version,omitempty" avro:"version"`
+}
+
+func NewTodoUpdated(item *todo.TodoItem) TodoUpdated {
+	return TodoUpdated{
+		Meta:        Meta{Type: TypeTodoUpdated, SchemaVersion: 1},
+		ID:          item.ID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		TimeZone:    item.TimeZone,
+		AllDay:      item.AllDay,
+		Recurrence:  item.Recurrence,
+		Version:     item.Version,
+	}
+}
+
+// TodoCompleted is published when a todo item is completed. For a
+// recurring todo NextID is the todo created for the next occurrence.
+type TodoCompleted struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	CompletedAt time.Time `json:"completedAt" avro:"completedAt"`
+	NextID      string    `json:"nextId,omitempty" avro:"nextId"`
+	Version     int       `json:"version,omitempty" avro:"version"`
+}
+
+func NewTodoCompleted(item *todo.TodoItem, next *todo.TodoItem) TodoCompleted {
+	e := TodoCompleted{
+		Meta:    Meta{Type: TypeTodoCompleted, SchemaVersion: 1},
+		ID:      item.ID,
+		Version: item.Version,
+	}
+	if item.CompletedAt != nil {
+		e.CompletedAt = *item.CompletedAt
+	}
+	if next != nil {
+		e.NextID = next.ID
+	}
+	return e
+}
+
+// TodoDeleted is published when a todo item is moved to the trash. Up to
+// v1 a deleted todo was gone for good; since v2 it can come back with
+// TodoRestored until TodoPurged removes it.
+type TodoDeleted struct {
+	Meta
+	ID        string    `json:"id" avro:"id"`
+	DeletedAt time.Time `json:"deletedAt" avro:"deletedAt"`
+	// Version is the version of the todo in the trash
+	Version int `json:"version,omitempty" avro:"version"`
+}
+
+func NewTodoDeleted(item *todo.TodoItem) TodoDeleted {
+	e := TodoDeleted{
+		Meta:    Meta{Type: TypeTodoDeleted, SchemaVersion: 2},
+		ID:      item.ID,
+		Version: item.Version,
+	}
+	if item.DeletedAt != nil {
+		e.DeletedAt = *item.DeletedAt
+	}
+	return e
+}
+
+// TodoRestored is published when a todo item is taken out of the trash. It
+// carries the whole todo, like TodoUpdated, for consumers that dropped it
+// on deletion.
+type TodoRestored struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	Description string    `json:"description" avro:"description"`
+	DueDate     time.Time `json:"dueDate" avro:"dueDate"`
+	TimeZone    string    `json:"timeZone,omitempty" avro:"timeZone"`
+	AllDay      bool      `json:"allDay,omitempty" avro:"allDay"`
+	Recurrence  string    `json:"recurrence,omitempty" avro:"recurrence"`
+	Completed   bool      `json:"completed,omitempty" avro:"completed"`
+	Version     int       `json:"version" avro:"version"`
+}
+
+func NewTodoRestored(item *todo.TodoItem) TodoRestored {
+	return TodoRestored{
+		Meta:        Meta{Type: TypeTodoRestored, SchemaVersion: 1},
+		ID:          item.ID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		TimeZone:    item.TimeZone,
+		AllDay:      item.AllDay,
+		Recurrence:  item.Recurrence,
+		Completed:   item.Completed,
+		Version:     item.Version,
+	}
+}
+
+// TodoPurged is published when a todo item is removed from the trash for
+// good, after which it cannot be restored
+type TodoPurged struct {
+	Meta
+	ID string `json:"id" avro:"id"`
+}
+
+func NewTodoPurged(item *todo.TodoItem) TodoPurged {
+	return TodoPurged{
+		Meta: Meta{Type: TypeTodoPurged, SchemaVersion: 1},
+		ID:   item.ID,
+	}
+}
+
+// TodoDueSoon is published once per reminder window when a todo item
+// approaches its due date
+type TodoDueSoon struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	Description string    `json:"description" avro:"description"`
+	DueDate     time.Time `json:"dueDate" avro:"dueDate"`
+	// Window names the reminder window, e.g. due_in_24h
+	Window string `json:"window" avro:"window"`
+}
+
+func NewTodoDueSoon(item *todo.TodoItem, window string) TodoDueSoon {
+	return TodoDueSoon{
+		Meta:        Meta{Type: TypeTodoDueSoon, SchemaVersion: 1},
+		ID:          item.ID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		Window:      window,
+	}
+}
+
+// TodoOverdue is published once when a todo item passes its due date
+type TodoOverdue struct {
+	Meta
+	ID          string    `json:"id" avro:"id"`
+	Description string    `json:"description" avro:"description"`
+	DueDate     time.Time `json:"dueDate" avro:"dueDate"`
+}
+
+func NewTodoOverdue(item *todo.TodoItem) TodoOverdue {
+	return TodoOverdue{
+		Meta:        Meta{Type: TypeTodoOverdue, SchemaVersion: 1},
+		ID:          item.ID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+	}
+}
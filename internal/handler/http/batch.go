@@ -0,0 +1,170 @@
+package http
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"ice/internal/todo"
+	"ice/pkg/errors"
+	"ice/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Batch runs several creates, updates and deletes in one request
+// @Summary Create, update and delete todo items in bulk
+// @Description Run up to HTTP_BATCH_MAX_OPERATIONS operations, in a body of at most HTTP_BATCH_MAX_BYTES, in a single transaction and report the outcome of each with the status its own endpoint would have answered. Operations are validated one by one; in best-effort mode failed operations are skipped, with atomic set any failure rolls the whole batch back and the others report 424.
+// @Description Answers 200 when every operation succeeded, 207 when some failed in best-effort mode and 422 when an atomic batch was rolled back.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param request body todo.BatchRequest true "Batch of operations"
+// @Param tz query string false "IANA time zone to render times in, defaults to each todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Success 200 {object} todo.BatchResponse
+// @Success 207 {object} todo.BatchResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 413 {object} errors.AppError
+// @Failure 422 {object} todo.BatchResponse
+// @Failure 500 {object} errors.AppError
+// @Router /todos:batch [post]
+func (h *TodoHandler) Batch(c echo.Context) error {
+	log := logger.Get()
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	var req todo.BatchRequest
+	if err := c.Bind(&req); err != nil {
+		if tooLarge(err) {
+			appErr := bodyTooLargeError(h.limits.BatchMaxBytes)
+			return c.JSON(appErr.Code, appErr)
+		}
+		log.Warn("Invalid request body", zap.Error(err))
+		appErr := errors.NewBadRequestError("invalid request body", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+	if n := len(req.Operations); n == 0 || n > h.limits.BatchMaxOperations {
+		appErr := errors.NewValidationError(fmt.Sprintf("operations must hold between 1 and %d entries", h.limits.BatchMaxOperations))
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	results := make([]error, len(req.Operations))
+	items := make([]*todo.TodoItem, len(req.Operations))
+	var (
+		ops     []todo.Operation
+		indexes []int
+		invalid bool
+	)
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		if err := h.validator.Validate(op); err != nil {
+			results[i] = errors.NewValidationError(err.Error())
+			invalid = true
+			continue
+		}
+
+		items[i] = batchItem(op)
+		ops = append(ops, todo.Operation{Kind: todo.OpKind(op.Op), Item: items[i]})
+		indexes = append(indexes, i)
+	}
+
+	switch {
+	case invalid && req.Atomic:
+		// nothing to run, the batch is rejected as a whole
+		for i := range results {
+			if results[i] == nil {
+				results[i] = todo.ErrBatchAborted
+			}
+		}
+	case len(ops) > 0:
+		opResults, err := h.service.Batch(c.Request().Context(), ops, req.Atomic)
+		if err != nil {
+			appErr := todoError(err, "failed to run batch")
+			return c.JSON(appErr.Code, appErr)
+		}
+		for j, i := range indexes {
+			results[i] = opResults[j]
+		}
+	}
+
+	resp := todo.BatchResponse{
+		Atomic:  req.Atomic,
+		Results: make([]todo.BatchResult, len(results)),
+	}
+	for i, err := range results {
+		result := todo.BatchResult{Index: i}
+		if err == nil {
+			result.Status = batchStatus(req.Operations[i].Op)
+			if req.Operations[i].Op != string(todo.OpDelete) {
+				rendered := render(items[i], zone)
+				result.TodoItem = &rendered
+			}
+			resp.Succeeded++
+		} else {
+			result.Status, result.Error = batchError(err)
+			resp.Failed++
+		}
+		resp.Results[i] = result
+	}
+
+	log.Info("Todo batch finished",
+		zap.Bool("atomic", req.Atomic),
+		zap.Int("succeeded", resp.Succeeded),
+		zap.Int("failed", resp.Failed),
+	)
+
+	switch {
+	case resp.Failed == 0:
+		return c.JSON(http.StatusOK, resp)
+	case req.Atomic:
+		return c.JSON(http.StatusUnprocessableEntity, resp)
+	default:
+		return c.JSON(http.StatusMultiStatus, resp)
+	}
+}
+
+// batchItem builds the todo an operation works on
+func batchItem(op *todo.BatchOperation) *todo.TodoItem {
+	item := &todo.TodoItem{ID: op.ID, Version: op.Version}
+	if op.Op == string(todo.OpCreate) {
+		item.ID = uuid.New().String()
+	}
+	if op.Todo != nil {
+		item.Description = op.Todo.Description
+		item.DueDate = op.Todo.DueDate
+		item.TimeZone = op.Todo.TimeZone
+		item.AllDay = op.Todo.AllDay
+		item.Recurrence = op.Todo.Recurrence
+	}
+	return item
+}
+
+// batchStatus is the status the endpoint of a successful operation answers
+func batchStatus(op string) int {
+	switch todo.OpKind(op) {
+	case todo.OpCreate:
+		return http.StatusCreated
+	case todo.OpDelete:
+		return http.StatusNoContent
+	}
+	return http.StatusOK
+}
+
+// batchError maps the error of an operation to its status and message
+func batchError(err error) (int, string) {
+	var appErr *errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Code, appErr.Message
+	}
+	if stderrors.Is(err, todo.ErrBatchAborted) {
+		return http.StatusFailedDependency, err.Error()
+	}
+	appErr = todoError(err, "batch operation failed")
+	return appErr.Code, appErr.Message
+}
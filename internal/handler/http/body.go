@@ -0,0 +1,40 @@
+package http
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"ice/pkg/errors"
+)
+
+// bodyLimitMiddleware answers 413 to requests whose body is larger than
+// limit, at once when Content-Length says so and otherwise once reading
+// goes past it; zero leaves the body unbounded
+func bodyLimitMiddleware(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if limit <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > limit {
+				appErr := bodyTooLargeError(limit)
+				return c.JSON(appErr.Code, appErr)
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, limit)
+			return next(c)
+		}
+	}
+}
+
+// tooLarge reports whether err comes from reading past the body limit
+func tooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return stderrors.As(err, &maxBytesErr)
+}
+
+func bodyTooLargeError(limit int64) *errors.AppError {
+	return errors.NewRequestEntityTooLargeError(fmt.Sprintf("request body is larger than %d bytes", limit))
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		// chunked hides the length so the limit is hit while reading
+		chunked  bool
+		limit    int64
+		wantCode int
+	}{
+		{name: "within the limit", body: `{"a":1}`, limit: 16, wantCode: http.StatusOK},
+		{name: "exactly the limit", body: `{"a":1}`, limit: 7, wantCode: http.StatusOK},
+		{name: "content length over the limit", body: `{"a":"long"}`, limit: 8, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "read past the limit", body: `{"a":"long"}`, chunked: true, limit: 8, wantCode: http.StatusRequestEntityTooLarge},
+		{name: "no limit", body: `{"a":"long"}`, chunked: true, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader = strings.NewReader(tt.body)
+			if tt.chunked {
+				body = io.MultiReader(body)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/", body)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			handler := bodyLimitMiddleware(tt.limit)(func(c echo.Context) error {
+				var v map[string]any
+				if err := c.Bind(&v); err != nil {
+					if tooLarge(err) {
+						appErr := bodyTooLargeError(tt.limit)
+						return c.JSON(appErr.Code, appErr)
+					}
+					return c.NoContent(http.StatusBadRequest)
+				}
+				return c.NoContent(http.StatusOK)
+			})
+			if err := handler(c); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
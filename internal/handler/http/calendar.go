@@ -0,0 +1,170 @@
+package http
+
+import (
+	stderrors "errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"ice/internal/audit"
+	"ice/internal/calendar"
+	"ice/internal/port"
+	"ice/internal/todo/exchange"
+	"ice/pkg/errors"
+	"ice/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// calendarFeedPath is the route of the feed, its URL carrying the token
+const calendarFeedPath = "/todos/calendar.ics"
+
+type CalendarHandler struct {
+	tokens port.CalendarService
+	todos  port.TodoService
+	// publicURL is the base of the feed URLs handed out, never taken from
+	// the request so a forged Host cannot redirect subscribers
+	publicURL string
+}
+
+func NewCalendarHandler(tokens port.CalendarService, todos port.TodoService, publicURL string) *CalendarHandler {
+	return &CalendarHandler{tokens: tokens, todos: todos, publicURL: strings.TrimSuffix(publicURL, "/")}
+}
+
+// tokenOwner is the actor a calendar token belongs to. Tokens are
+// credentials, so the actor must be vouched for by the gateway rather
+// than merely claimed.
+func tokenOwner(c echo.Context) (string, *errors.AppError) {
+	if !audit.SourceFrom(c.Request().Context()).Verified {
+		return "", errors.NewUnauthorizedError("calendar tokens are only handed to actors vouched for by the gateway")
+	}
+	owner := strings.TrimSpace(c.Request().Header.Get(headerActor))
+	if owner == "" {
+		return "", errors.NewValidationError("X-Actor header is required to own a calendar token")
+	}
+	return owner, nil
+}
+
+// IssueToken hands out the secret URL of the calendar feed
+// @Summary Issue a calendar feed token
+// @Description Issue a secret token for the calendar feed to the actor named by X-Actor and return the feed URL, based on HTTP_PUBLIC_URL, to subscribe to. The request must come through the gateway with X-Gateway-Token, as the token grants read access to every todo. An actor has one token at a time; issuing a new one revokes the old URL. The token is only shown in this response.
+// @Tags calendar
+// @Produce json
+// @Param X-Actor header string true "Owner of the token"
+// @Param X-Gateway-Token header string true "Secret of the gateway vouching for X-Actor"
+// @Success 201 {object} calendar.TokenResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todos/calendar/token [post]
+func (h *CalendarHandler) IssueToken(c echo.Context) error {
+	log := logger.Get()
+
+	owner, appErr := tokenOwner(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	token, secret, err := h.tokens.IssueToken(c.Request().Context(), owner)
+	if err != nil {
+		log.Error("Failed to issue calendar token", zap.String("owner", owner), zap.Error(err))
+		appErr := errors.NewInternalError("failed to issue calendar token", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	log.Info("Calendar token issued", zap.String("owner", owner))
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusCreated, calendar.TokenResponse{
+		Token:     secret,
+		URL:       h.publicURL + calendarFeedPath + "?token=" + url.QueryEscape(secret),
+		Owner:     token.Owner,
+		CreatedAt: token.CreatedAt,
+	})
+}
+
+// RevokeToken revokes the calendar feed token of an actor
+// @Summary Revoke a calendar feed token
+// @Description Revoke the calendar feed token of the actor named by X-Actor, vouched for by the gateway with X-Gateway-Token; its feed URL stops working at once.
+// @Tags calendar
+// @Param X-Actor header string true "Owner of the token"
+// @Param X-Gateway-Token header string true "Secret of the gateway vouching for X-Actor"
+// @Success 204
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todos/calendar/token [delete]
+func (h *CalendarHandler) RevokeToken(c echo.Context) error {
+	owner, appErr := tokenOwner(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	err := h.tokens.RevokeToken(c.Request().Context(), owner)
+	if stderrors.Is(err, calendar.ErrTokenNotFound) {
+		appErr := errors.NewNotFoundError(err.Error())
+		return c.JSON(appErr.Code, appErr)
+	}
+	if err != nil {
+		logger.Get().Error("Failed to revoke calendar token", zap.String("owner", owner), zap.Error(err))
+		appErr := errors.NewInternalError("failed to revoke calendar token", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	logger.Get().Info("Calendar token revoked", zap.String("owner", owner))
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Feed serves the todos as an iCalendar feed
+// @Summary Subscribe to todos as a calendar
+// @Description iCalendar feed of all todo items, trashed ones left out, for calendar apps to subscribe to. Todos have no owner, so every token reads the same global feed; the owner only names who the token was issued to. Each todo keeps its id as UID, so updates replace the entry rather than duplicate it. Timed todos are written in their own zone with a VTIMEZONE, all-day ones as dates, and recurring ones with their RRULE.
+// @Description The token comes from POST /todos/calendar/token. By default todos are VEVENTs, which every calendar app shows; component=vtodo writes VTODOs with their completion state for task apps.
+// @Tags calendar
+// @Produce text/calendar
+// @Param token query string true "Secret feed token"
+// @Param component query string false "Component todos are written as" Enums(vevent, vtodo) default(vevent)
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} errors.AppError
+// @Failure 401 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todos/calendar.ics [get]
+func (h *CalendarHandler) Feed(c echo.Context) error {
+	log := logger.Get()
+
+	token, err := h.tokens.Authenticate(c.Request().Context(), c.QueryParam("token"))
+	if stderrors.Is(err, calendar.ErrTokenNotFound) {
+		appErr := errors.NewUnauthorizedError("invalid calendar token")
+		return c.JSON(appErr.Code, appErr)
+	}
+	if err != nil {
+		log.Error("Failed to check calendar token", zap.Error(err))
+		appErr := errors.NewInternalError("failed to check calendar token", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	component := exchange.Event
+	if s := c.QueryParam("component"); s != "" {
+		var ok bool
+		if component, ok = exchange.ParseComponent(s); !ok {
+			appErr := errors.NewValidationError("component must be vevent or vtodo")
+			return c.JSON(appErr.Code, appErr)
+		}
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, exchange.ICS.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, `inline; filename="todos.ics"`)
+	// the URL is a credential, shared caches must not keep the feed
+	res.Header().Set("Cache-Control", "private, no-cache")
+	res.WriteHeader(http.StatusOK)
+
+	rows, err := exportTodos(c, h.todos, exchange.NewICSWriter(res, component), nil)
+	if err != nil {
+		log.Error("Calendar feed failed", zap.String("owner", token.Owner), zap.Int("rows", rows), zap.Error(err))
+		return nil
+	}
+
+	log.Info("Calendar feed served", zap.String("owner", token.Owner), zap.Int("rows", rows))
+	return nil
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ice/internal/calendar"
+
+	"github.com/labstack/echo/v4"
+)
+
+// fakeTokens issues the secret "s3cret" to anyone
+type fakeTokens struct {
+	issued []string
+}
+
+func (f *fakeTokens) IssueToken(_ context.Context, owner string) (*calendar.Token, string, error) {
+	f.issued = append(f.issued, owner)
+	return &calendar.Token{Owner: owner, CreatedAt: time.Now()}, "s3cret", nil
+}
+
+func (f *fakeTokens) RevokeToken(context.Context, string) error {
+	return nil
+}
+
+func (f *fakeTokens) Authenticate(context.Context, string) (*calendar.Token, error) {
+	return nil, calendar.ErrTokenNotFound
+}
+
+func TestIssueToken(t *testing.T) {
+	const gatewayToken = "gw"
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		wantCode int
+		wantURL  string
+	}{
+		{
+			name:     "vouched for by the gateway",
+			headers:  map[string]string{headerActor: "alice", headerGatewayToken: gatewayToken},
+			wantCode: http.StatusCreated,
+			wantURL:  "https://todos.example.com/todos/calendar.ics?token=s3cret",
+		},
+		{
+			name:     "forged host is ignored",
+			headers:  map[string]string{headerActor: "alice", headerGatewayToken: gatewayToken, "Host": "evil.example.com"},
+			wantCode: http.StatusCreated,
+			wantURL:  "https://todos.example.com/todos/calendar.ics?token=s3cret",
+		},
+		{
+			name:     "claimed actor",
+			headers:  map[string]string{headerActor: "alice"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "wrong gateway token",
+			headers:  map[string]string{headerActor: "alice", headerGatewayToken: "guess"},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:     "no actor",
+			headers:  map[string]string{headerGatewayToken: gatewayToken},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &fakeTokens{}
+			h := NewCalendarHandler(tokens, nil, "https://todos.example.com/")
+			handler := auditSourceMiddleware(gatewayToken)(h.IssueToken)
+
+			req := httptest.NewRequest(http.MethodPost, "/todos/calendar/token", nil)
+			for name, value := range tt.headers {
+				if name == "Host" {
+					req.Host = value
+					continue
+				}
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			if err := handler(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body)
+			}
+			if tt.wantCode != http.StatusCreated {
+				if len(tokens.issued) != 0 {
+					t.Errorf("issued a token to %v", tokens.issued)
+				}
+				return
+			}
+			var resp calendar.TokenResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.URL != tt.wantURL {
+				t.Errorf("URL = %q, want %q", resp.URL, tt.wantURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	stderrors "errors"
+	"strconv"
+	"strings"
+
+	"ice/internal/todo"
+	"ice/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Conditional request headers
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// etag is the strong entity tag of a todo, its quoted version
+func etag(item *todo.TodoItem) string {
+	return `"` + strconv.Itoa(item.Version) + `"`
+}
+
+// setETag tags the response with the todo version. The representation
+// also depends on the requested zone, which the header variant is declared
+// for; the tz query parameter is part of the URL already.
+func setETag(c echo.Context, item *todo.TodoItem) {
+	c.Response().Header().Set(headerETag, etag(item))
+	c.Response().Header().Add(echo.HeaderVary, "X-Time-Zone")
+}
+
+// ifMatch returns the todo versions a write is conditioned on by If-Match,
+// a single zero for "*". A missing header fails with 428 when required, a
+// header listing no tag that can match a version, e.g. only weak tags,
+// fails with 412.
+func ifMatch(c echo.Context, required bool) ([]int, *errors.AppError) {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	switch {
+	case header == "" && required:
+		return nil, errors.NewPreconditionRequiredError("If-Match header with the todo ETag is required")
+	case header == "", header == "*":
+		return []int{0}, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(header, ",") {
+		// weak tags never match under the strong comparison If-Match uses
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+			continue
+		}
+		if version, err := strconv.Atoi(tag[1 : len(tag)-1]); err == nil && version > 0 {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, errors.NewPreconditionFailedError("If-Match does not match the todo ETag")
+	}
+	return versions, nil
+}
+
+// eachVersion runs write with the versions ifMatch returned until one of
+// them is the current one. Every attempt checks the version in its own
+// transaction, so the ones that do not match change nothing.
+func eachVersion(versions []int, write func(version int) error) error {
+	var err error
+	for _, version := range versions {
+		if err = write(version); !stderrors.Is(err, todo.ErrVersionConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+// notModified reports whether If-None-Match already holds the current
+// ETag of item
+func notModified(c echo.Context, item *todo.TodoItem) bool {
+	header := c.Request().Header.Get(headerIfNoneMatch)
+	if header == "" {
+		return false
+	}
+	tag := etag(item)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
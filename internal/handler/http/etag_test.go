@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"ice/internal/todo"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		required bool
+		want     []int
+		wantCode int
+	}{
+		{name: "missing and optional", want: []int{0}},
+		{name: "missing and required", required: true, wantCode: http.StatusPreconditionRequired},
+		{name: "any version", header: "*", required: true, want: []int{0}},
+		{name: "single tag", header: `"3"`, required: true, want: []int{3}},
+		{name: "list", header: `"3", "4"`, want: []int{3, 4}},
+		{name: "list without spaces", header: `"3","4"`, want: []int{3, 4}},
+		{name: "weak tags left out", header: `W/"2", "5"`, want: []int{5}},
+		{name: "only weak tags", header: `W/"2"`, wantCode: http.StatusPreconditionFailed},
+		{name: "unquoted", header: `3`, wantCode: http.StatusPreconditionFailed},
+		{name: "foreign tag", header: `"abc"`, wantCode: http.StatusPreconditionFailed},
+		{name: "zero version", header: `"0"`, wantCode: http.StatusPreconditionFailed},
+		{name: "foreign tag in list", header: `"abc", "7"`, want: []int{7}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := conditional(headerIfMatch, tt.header)
+			got, appErr := ifMatch(c, tt.required)
+			if tt.wantCode != 0 {
+				if appErr == nil || appErr.Code != tt.wantCode {
+					t.Fatalf("ifMatch(%q) = %v, %v, want code %d", tt.header, got, appErr, tt.wantCode)
+				}
+				return
+			}
+			if appErr != nil {
+				t.Fatalf("ifMatch(%q): %v", tt.header, appErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ifMatch(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	item := &todo.TodoItem{Version: 3}
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{name: "missing"},
+		{name: "current", header: `"3"`, want: true},
+		{name: "older", header: `"2"`},
+		{name: "weak current", header: `W/"3"`, want: true},
+		{name: "list with current", header: `"1", "3"`, want: true},
+		{name: "any", header: "*", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notModified(conditional(headerIfNoneMatch, tt.header), item); got != tt.want {
+				t.Errorf("notModified(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEachVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []int
+		current  int
+		wantErr  error
+		wantTry  []int
+	}{
+		{name: "first matches", versions: []int{3, 4}, current: 3, wantTry: []int{3}},
+		{name: "later matches", versions: []int{3, 4}, current: 4, wantTry: []int{3, 4}},
+		{name: "none matches", versions: []int{3, 4}, current: 5, wantErr: todo.ErrVersionConflict, wantTry: []int{3, 4}},
+		{name: "other errors stop", versions: []int{3, 4}, current: -1, wantErr: todo.ErrNotFound, wantTry: []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tried []int
+			err := eachVersion(tt.versions, func(version int) error {
+				tried = append(tried, version)
+				switch {
+				case tt.current < 0:
+					return todo.ErrNotFound
+				case version != tt.current:
+					return todo.ErrVersionConflict
+				}
+				return nil
+			})
+			if err != tt.wantErr {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tried, tt.wantTry) {
+				t.Errorf("tried %v, want %v", tried, tt.wantTry)
+			}
+		})
+	}
+}
+
+// conditional builds a request context carrying header, unless empty
+func conditional(name, value string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/todo/1", nil)
+	if value != "" {
+		req.Header.Set(name, value)
+	}
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
@@ -0,0 +1,250 @@
+package http
+
+import (
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"ice/internal/port"
+	"ice/internal/todo"
+	"ice/internal/todo/exchange"
+	"ice/pkg/errors"
+	"ice/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Records handled together while exporting and importing
+const (
+	exportFlushRows = 500
+	importChunkRows = 500
+)
+
+// Export streams every todo item
+// @Summary Export todo items
+// @Description Stream all todo items, trashed ones left out, as CSV, a JSON array, NDJSON or an iCalendar file of VEVENTs. All-day due dates are written as plain dates in CSV, and CSV cells a spreadsheet would run as a formula are prefixed with an apostrophe. The output can be imported again.
+// @Tags todos
+// @Produce json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce text/calendar
+// @Param format query string false "Output format" Enums(csv, json, ndjson, ics) default(json)
+// @Param tz query string false "IANA time zone to write times in, defaults to each todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to write times in when tz is not given"
+// @Success 200 {array} exchange.Record
+// @Failure 400 {object} errors.AppError
+// @Router /todos/export [get]
+func (h *TodoHandler) Export(c echo.Context) error {
+	log := logger.Get()
+
+	format := exchange.JSON
+	if s := c.QueryParam("format"); s != "" {
+		f, err := exchange.ParseFormat(s)
+		if err != nil {
+			appErr := errors.NewBadRequestError(err.Error(), err)
+			return c.JSON(appErr.Code, appErr)
+		}
+		format = f
+	}
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"todos.%s\"", format))
+	res.WriteHeader(http.StatusOK)
+
+	rows, err := exportTodos(c, h.service, exchange.NewWriter(format, res), zone)
+	if err != nil {
+		// the status is sent already, the client sees a truncated body
+		log.Error("Todo export failed", zap.Int("rows", rows), zap.Error(err))
+		return nil
+	}
+
+	log.Info("Todos exported", zap.String("format", string(format)), zap.Int("rows", rows))
+	return nil
+}
+
+// exportTodos streams every todo to w, which writes to the response,
+// rendered in zone
+func exportTodos(c echo.Context, s port.TodoService, w exchange.Writer, zone *time.Location) (int, error) {
+	rows := 0
+	err := s.ExportTodos(c.Request().Context(), func(item *todo.TodoItem) error {
+		if err := w.Write(exchange.FromItem(render(item, zone))); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			c.Response().Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return rows, err
+	}
+	return rows, w.Close()
+}
+
+// importRow is a valid record waiting to be imported
+type importRow struct {
+	number int
+	item   *todo.TodoItem
+}
+
+// Import creates todo items from a file
+// @Summary Import todo items
+// @Description Create todo items from CSV, a JSON array, NDJSON or an iCalendar file, in the format of the export. From iCalendar every VEVENT and VTODO becomes a todo, due at its DUE or DTSTART, with its UID as externalId and its SUMMARY as description. The format is taken from the format parameter, else from the Content-Type. The whole input is read before anything is written: when it cannot be parsed, holds more than HTTP_IMPORT_MAX_ROWS records or is larger than HTTP_IMPORT_MAX_BYTES nothing is imported.
+// @Description Invalid records are reported and skipped. A record without externalId takes its exported id as one. Records whose externalId is already taken, by a todo or an earlier record of the file, are not created again. Records are committed 500 at a time; when the import fails midway the report says what was created, and running it again only adds the rest of the records with an external ID.
+// @Tags todos
+// @Accept json
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept text/calendar
+// @Produce json
+// @Param format query string false "Input format, defaults to the Content-Type" Enums(csv, json, ndjson, ics)
+// @Param dryRun query bool false "Validate and report without creating anything" default(false)
+// @Param request body []exchange.Record true "Todo items to import"
+// @Success 200 {object} exchange.ImportResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 413 {object} errors.AppError
+// @Failure 422 {object} exchange.ImportResponse
+// @Failure 500 {object} exchange.ImportResponse
+// @Router /todos/import [post]
+func (h *TodoHandler) Import(c echo.Context) error {
+	log := logger.Get()
+	req := c.Request()
+
+	var (
+		format exchange.Format
+		err    error
+	)
+	if s := c.QueryParam("format"); s != "" {
+		format, err = exchange.ParseFormat(s)
+	} else {
+		format, err = exchange.FormatOf(req.Header.Get(echo.HeaderContentType))
+	}
+	if err != nil {
+		appErr := errors.NewBadRequestError(err.Error(), err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	dryRun := false
+	if s := c.QueryParam("dryRun"); s != "" {
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			appErr := errors.NewBadRequestError("dryRun must be true or false", err)
+			return c.JSON(appErr.Code, appErr)
+		}
+	}
+
+	r, err := exchange.NewReader(format, req.Body)
+	if err != nil {
+		if tooLarge(err) {
+			appErr := bodyTooLargeError(h.limits.ImportMaxBytes)
+			return c.JSON(appErr.Code, appErr)
+		}
+		appErr := errors.NewBadRequestError(err.Error(), err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	resp := exchange.ImportResponse{
+		DryRun:     dryRun,
+		Errors:     []exchange.ImportRowError{},
+		Duplicates: []exchange.ImportDuplicate{},
+	}
+	fail := func(number int, externalID string, err error) {
+		resp.Failed++
+		resp.Errors = append(resp.Errors, exchange.ImportRowError{Row: number, ExternalID: externalID, Error: err.Error()})
+	}
+
+	// external IDs of the file, to catch a record repeating an earlier one
+	seen := make(map[string]int)
+	var rows []importRow
+	for {
+		row, err := r.Next()
+		if stderrors.Is(err, io.EOF) {
+			break
+		}
+		if tooLarge(err) {
+			// nothing was written yet
+			appErr := bodyTooLargeError(h.limits.ImportMaxBytes)
+			return c.JSON(appErr.Code, appErr)
+		}
+		if err != nil {
+			resp.Error = fmt.Sprintf("record %d: %v", resp.Total+1, err)
+			return c.JSON(http.StatusUnprocessableEntity, resp)
+		}
+
+		resp.Total++
+		if resp.Total > h.limits.ImportMaxRows {
+			resp.Error = fmt.Sprintf("more than %d records", h.limits.ImportMaxRows)
+			return c.JSON(http.StatusUnprocessableEntity, resp)
+		}
+
+		rec := row.Record
+		if row.Err != nil {
+			fail(row.Number, rec.ExternalID, row.Err)
+			continue
+		}
+		if err := h.validator.Validate(&rec); err != nil {
+			fail(row.Number, rec.ExternalID, err)
+			continue
+		}
+		if rec.ExternalID != "" {
+			if first, ok := seen[rec.ExternalID]; ok {
+				fail(row.Number, rec.ExternalID, fmt.Errorf("externalId repeats record %d", first))
+				continue
+			}
+			seen[rec.ExternalID] = row.Number
+		}
+		item := rec.Item()
+		item.ID = uuid.New().String()
+		rows = append(rows, importRow{number: row.Number, item: item})
+	}
+
+	ctx := req.Context()
+	for start := 0; start < len(rows); start += importChunkRows {
+		chunk := rows[start:min(start+importChunkRows, len(rows))]
+		items := make([]*todo.TodoItem, len(chunk))
+		for i, row := range chunk {
+			items[i] = row.item
+		}
+
+		duplicates, err := h.service.ImportTodos(ctx, items, dryRun)
+		if err != nil {
+			log.Error("Todo import failed", zap.Int("created", resp.Created), zap.Error(err))
+			resp.Error = "failed to import todos"
+			return c.JSON(http.StatusInternalServerError, resp)
+		}
+		for i, row := range chunk {
+			if id := duplicates[i]; id != "" {
+				resp.Skipped++
+				resp.Duplicates = append(resp.Duplicates, exchange.ImportDuplicate{
+					Row:        row.number,
+					ExternalID: row.item.ExternalID,
+					ID:         id,
+				})
+				continue
+			}
+			resp.Created++
+		}
+	}
+
+	log.Info("Todos imported",
+		zap.String("format", string(format)),
+		zap.Bool("dry_run", dryRun),
+		zap.Int("created", resp.Created),
+		zap.Int("skipped", resp.Skipped),
+		zap.Int("failed", resp.Failed),
+	)
+	return c.JSON(http.StatusOK, resp)
+}
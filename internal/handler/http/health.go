@@ -1,69 +1,86 @@
 package http
 
 import (
-	"context"
-	"database/sql"
 	"net/http"
-	"time"
+
+	"ice/internal/health"
 
 	"github.com/labstack/echo/v4"
-	"github.com/redis/go-redis/v9"
 )
 
-// HealthCheck checks the health of the service
-// @Summary Health check endpoint
-// @Description Check the health status of the service, MySQL, and Redis
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// Livez reports whether the process is alive
+// @Summary Liveness probe
+// @Description Succeeds as long as the process can serve requests; it does not depend on MySQL or Redis
 // @Tags health
 // @Produce json
-// @Success 200 {object} map[string]string
-// @Success 503 {object} map[string]string
-// @Router /health [get]
-
-type HealthChecker struct {
-	mysql *sql.DB
-	redis *redis.Client
+// @Param verbose query bool false "List every check with its latency"
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /livez [get]
+func (h *HealthHandler) Livez(c echo.Context) error {
+	return h.probe(c, health.Liveness)
 }
 
-func NewHealthChecker(mysql *sql.DB, redis *redis.Client) *HealthChecker {
-	return &HealthChecker{
-		mysql: mysql,
-		redis: redis,
-	}
+// Readyz reports whether the service should receive traffic
+// @Summary Readiness probe
+// @Description Fails when MySQL is unreachable; Redis and outbox lag only degrade the status since the outbox buffers events
+// @Tags health
+// @Produce json
+// @Param verbose query bool false "List every check with its latency"
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c echo.Context) error {
+	return h.probe(c, health.Readiness)
 }
 
-func (h *HealthChecker) HealthCheck(c echo.Context) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
+// Startupz reports whether the service has finished starting
+// @Summary Startup probe
+// @Description Fails until MySQL is reachable and the schema is migrated to the version the binary expects
+// @Tags health
+// @Produce json
+// @Param verbose query bool false "List every check with its latency"
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /startupz [get]
+func (h *HealthHandler) Startupz(c echo.Context) error {
+	return h.probe(c, health.Startup)
+}
 
-	status := map[string]string{
-		"status": "ok",
-	}
+// HealthCheck checks the health of the service
+// @Summary Health check endpoint
+// @Description Verbose readiness report, kept for existing monitors
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report
+// @Router /health [get]
+func (h *HealthHandler) HealthCheck(c echo.Context) error {
+	report := h.registry.Run(c.Request().Context(), health.Readiness)
+	return c.JSON(statusCode(report), report)
+}
 
-	// Check MySQL
-	if h.mysql != nil {
-		if err := h.mysql.PingContext(ctx); err != nil {
-			status["mysql"] = "unhealthy"
-			status["status"] = "degraded"
-		} else {
-			status["mysql"] = "healthy"
-		}
-	}
+func (h *HealthHandler) probe(c echo.Context, p health.Probe) error {
+	report := h.registry.Run(c.Request().Context(), p)
 
-	// Check Redis
-	if h.redis != nil {
-		if err := h.redis.Ping(ctx).Err(); err != nil {
-			status["redis"] = "unhealthy"
-			status["status"] = "degraded"
-		} else {
-			status["redis"] = "healthy"
-		}
+	verbose := c.QueryParam("verbose")
+	if verbose == "" || verbose == "false" || verbose == "0" {
+		report.Checks = nil
 	}
+	return c.JSON(statusCode(report), report)
+}
 
-	statusCode := http.StatusOK
-	if status["status"] == "degraded" {
-		statusCode = http.StatusServiceUnavailable
+func statusCode(report health.Report) int {
+	if report.Status == health.StatusUnavailable {
+		return http.StatusServiceUnavailable
 	}
-
-	return c.JSON(statusCode, status)
+	return http.StatusOK
 }
-
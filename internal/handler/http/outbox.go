@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"ice/internal/outbox"
+	"ice/internal/port"
+	"ice/pkg/errors"
+	"ice/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type OutboxHandler struct {
+	monitor    port.OutboxMonitor
+	thresholds outbox.Thresholds
+}
+
+func NewOutboxHandler(monitor port.OutboxMonitor, thresholds outbox.Thresholds) *OutboxHandler {
+	return &OutboxHandler{monitor: monitor, thresholds: thresholds}
+}
+
+// Status reports the outbox backlog
+// @Summary Outbox status
+// @Description Pending and failed counts, age of the oldest pending message and last publish time; status is degraded beyond the configured thresholds
+// @Tags admin
+// @Produce json
+// @Success 200 {object} outbox.StatusResponse
+// @Failure 500 {object} errors.AppError
+// @Router /admin/outbox/status [get]
+func (h *OutboxHandler) Status(c echo.Context) error {
+	stats, err := h.monitor.Stats(c.Request().Context())
+	if err != nil {
+		logger.Get().Error("Failed to read outbox stats", zap.Error(err))
+		appErr := errors.NewInternalError("failed to read outbox stats", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	return c.JSON(http.StatusOK, outbox.NewStatusResponse(stats, h.thresholds.Problems(stats)))
+}
@@ -1,44 +1,92 @@
 package http
 
 import (
-	"database/sql"
+	"crypto/subtle"
+	"net"
 	"net/http"
+	"strings"
 	"time"
 
 	_ "ice/docs" // swagger docs
+	"ice/internal/audit"
+	"ice/internal/health"
+	"ice/internal/outbox"
 	"ice/internal/port"
 	"ice/pkg/logger"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/redis/go-redis/v9"
 	echoSwagger "github.com/swaggo/echo-swagger"
 	"go.uber.org/zap"
 )
 
 type ServerDependencies struct {
-	TodoService port.TodoService
-	MySQL       *sql.DB
-	Redis       *redis.Client
+	TodoService     port.TodoService
+	TodoLimits      TodoLimits
+	CalendarService port.CalendarService
+	Health          *health.Registry
+	Outbox          port.OutboxMonitor
+	// OutboxThresholds mark the admin outbox status as degraded
+	OutboxThresholds outbox.Thresholds
+	// PublicURL is the base URL clients reach the service at, e.g.
+	// https://todos.example.com, used in the calendar feed URLs
+	PublicURL string
+	// TrustedProxies are the CIDRs of the proxies whose X-Forwarded-For
+	// is believed; without any the client IP is the peer address
+	TrustedProxies []string
+	// GatewayToken is the secret the gateway sends in X-Gateway-Token to
+	// vouch for X-Actor; empty trusts no request
+	GatewayToken string
 }
 
 func NewServer(deps ServerDependencies, port string) *echo.Echo {
 	e := echo.New()
+	e.IPExtractor = ipExtractor(deps.TrustedProxies)
 
 	// Middleware
+	e.Use(middleware.RequestID())
 	e.Use(zapLoggerMiddleware())
 	e.Use(middleware.Recover())
+	e.Use(auditSourceMiddleware(deps.GatewayToken))
 
 	// Routes
-	todoHandler := NewTodoHandler(deps.TodoService)
+	todoHandler := NewTodoHandler(deps.TodoService, deps.TodoLimits)
 	e.POST("/todo", todoHandler.CreateTodo)
+	e.GET("/todo/:id", todoHandler.GetTodo)
+	e.PUT("/todo/:id", todoHandler.UpdateTodo)
+	e.DELETE("/todo/:id", todoHandler.DeleteTodo)
+	e.POST("/todo/:id/complete", todoHandler.CompleteTodo)
+	e.POST("/todo/:id/restore", todoHandler.RestoreTodo)
+	e.GET("/todo/:id/history", todoHandler.History)
+	e.GET("/todos/trash", todoHandler.ListTrash)
+	e.GET("/todos/export", todoHandler.Export)
+	e.POST("/todos/import", todoHandler.Import, bodyLimitMiddleware(deps.TodoLimits.ImportMaxBytes))
+	// the colon is literal, not a path parameter
+	e.POST("/todos\\:batch", todoHandler.Batch, bodyLimitMiddleware(deps.TodoLimits.BatchMaxBytes))
+
+	// Calendar
+	if deps.CalendarService != nil {
+		calendarHandler := NewCalendarHandler(deps.CalendarService, deps.TodoService, deps.PublicURL)
+		e.POST("/todos/calendar/token", calendarHandler.IssueToken)
+		e.DELETE("/todos/calendar/token", calendarHandler.RevokeToken)
+		e.GET(calendarFeedPath, calendarHandler.Feed)
+	}
 
-	// Health check
-	if deps.MySQL != nil && deps.Redis != nil {
-		healthChecker := NewHealthChecker(deps.MySQL, deps.Redis)
-		e.GET("/health", healthChecker.HealthCheck)
-	} else {
-		e.GET("/health", healthCheck)
+	// Health checks
+	registry := deps.Health
+	if registry == nil {
+		registry = health.NewRegistry(0, 0)
+	}
+	healthHandler := NewHealthHandler(registry)
+	e.GET("/livez", healthHandler.Livez)
+	e.GET("/readyz", healthHandler.Readyz)
+	e.GET("/startupz", healthHandler.Startupz)
+	e.GET("/health", healthHandler.HealthCheck)
+
+	// Admin
+	if deps.Outbox != nil {
+		outboxHandler := NewOutboxHandler(deps.Outbox, deps.OutboxThresholds)
+		e.GET("/admin/outbox/status", outboxHandler.Status)
 	}
 
 	// Swagger documentation
@@ -76,6 +124,7 @@ func zapLoggerMiddleware() echo.MiddlewareFunc {
 				zap.Int("status", res.Status),
 				zap.Duration("latency", time.Since(start)),
 				zap.String("ip", c.RealIP()),
+				zap.String("request_id", res.Header().Get(echo.HeaderXRequestID)),
 			)
 
 			return err
@@ -83,6 +132,61 @@ func zapLoggerMiddleware() echo.MiddlewareFunc {
 	}
 }
 
-func healthCheck(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+// headerActor names the user or service behind a request. There is no
+// authentication in the service itself, the header is expected to be set
+// by the gateway in front of it, which proves so with headerGatewayToken.
+const (
+	headerActor        = "X-Actor"
+	headerGatewayToken = "X-Gateway-Token"
+)
+
+// auditSourceMiddleware binds the actor, request ID and client IP of the
+// request to its context for the audit log. The actor is verified only
+// when the request carries gatewayToken.
+func auditSourceMiddleware(gatewayToken string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := audit.WithSource(req.Context(), audit.Source{
+				Actor:     strings.TrimSpace(req.Header.Get(headerActor)),
+				Verified:  fromGateway(req, gatewayToken),
+				RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+				ClientIP:  c.RealIP(),
+			})
+			c.SetRequest(req.WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// ipExtractor reads the client IP from X-Forwarded-For when the request
+// came through one of the trusted proxies, and otherwise takes the peer
+// address, so a client cannot name its own IP
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	var ranges []echo.TrustOption
+	for _, cidr := range trustedProxies {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			logger.Get().Warn("Ignoring invalid trusted proxy", zap.String("cidr", cidr), zap.Error(err))
+			continue
+		}
+		ranges = append(ranges, echo.TrustIPRange(ipNet))
+	}
+	if len(ranges) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	// only the configured ranges, not every private address
+	options := append([]echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}, ranges...)
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// fromGateway reports whether req carries gatewayToken, which is never
+// the case when it is empty
+func fromGateway(req *http.Request, gatewayToken string) bool {
+	sent := req.Header.Get(headerGatewayToken)
+	return gatewayToken != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(gatewayToken)) == 1
 }
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ice/internal/audit"
+	"ice/internal/todo"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestAuditSource(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		headers        map[string]string
+		wantIP         string
+		wantRequestID  string
+	}{
+		{
+			name:       "peer address",
+			remoteAddr: "203.0.113.7:52100",
+			wantIP:     "203.0.113.7",
+		},
+		{
+			name:       "forged forwarding headers",
+			remoteAddr: "203.0.113.7:52100",
+			headers:    map[string]string{echo.HeaderXForwardedFor: "198.51.100.1", echo.HeaderXRealIP: "198.51.100.2"},
+			wantIP:     "203.0.113.7",
+		},
+		{
+			name:           "forwarded by a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:52100",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"},
+			wantIP:         "198.51.100.1",
+		},
+		{
+			name:           "forwarded by an untrusted private address",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.168.1.5:52100",
+			headers:        map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"},
+			wantIP:         "192.168.1.5",
+		},
+		{
+			name:           "oversized forwarded address falls back to the proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.1.2.3:52100",
+			headers:        map[string]string{echo.HeaderXForwardedFor: strings.Repeat("x", 100)},
+			wantIP:         "10.1.2.3",
+		},
+		{
+			name:          "request id",
+			remoteAddr:    "203.0.113.7:52100",
+			headers:       map[string]string{echo.HeaderXRequestID: "3f2b0c9e7d1a4e58"},
+			wantIP:        "203.0.113.7",
+			wantRequestID: "3f2b0c9e7d1a4e58",
+		},
+		{
+			name:          "oversized request id",
+			remoteAddr:    "203.0.113.7:52100",
+			headers:       map[string]string{echo.HeaderXRequestID: strings.Repeat("r", 300)},
+			wantIP:        "203.0.113.7",
+			wantRequestID: strings.Repeat("r", 128),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.IPExtractor = ipExtractor(tt.trustedProxies)
+
+			req := httptest.NewRequest(http.MethodPost, "/todo", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if id := req.Header.Get(echo.HeaderXRequestID); id != "" {
+				// as the request ID middleware does
+				c.Response().Header().Set(echo.HeaderXRequestID, id)
+			}
+
+			var entry audit.Entry
+			handler := auditSourceMiddleware("")(func(c echo.Context) error {
+				entry = audit.NewEntry(c.Request().Context(), audit.ActionCreate, nil, &todo.TodoItem{ID: "a"})
+				return nil
+			})
+			if err := handler(c); err != nil {
+				t.Fatal(err)
+			}
+
+			if entry.ClientIP != tt.wantIP {
+				t.Errorf("ClientIP = %q, want %q", entry.ClientIP, tt.wantIP)
+			}
+			if entry.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %q, want %q", entry.RequestID, tt.wantRequestID)
+			}
+		})
+	}
+}
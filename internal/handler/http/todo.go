@@ -1,26 +1,52 @@
 package http
 
 import (
+	stderrors "errors"
+	"fmt"
+	"strconv"
+
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"ice/internal/audit"
 	"ice/internal/port"
 	"ice/internal/todo"
 	"ice/pkg/errors"
 	"ice/pkg/logger"
 	"ice/pkg/validator"
+	"time"
 
 	"go.uber.org/zap"
 )
 
+// TodoLimits bound the bulk endpoints, zero picks the default
+type TodoLimits struct {
+	// BatchMaxOperations caps the operations of a batch
+	BatchMaxOperations int
+	// ImportMaxRows caps the records of an import
+	ImportMaxRows int
+	// BatchMaxBytes and ImportMaxBytes cap the request bodies of batches
+	// and imports, zero leaves them unbounded
+	BatchMaxBytes  int64
+	ImportMaxBytes int64
+}
+
 type TodoHandler struct {
 	service   port.TodoService
 	validator *validator.Validator
+	limits    TodoLimits
 }
 
-func NewTodoHandler(s port.TodoService) *TodoHandler {
+func NewTodoHandler(s port.TodoService, limits TodoLimits) *TodoHandler {
+	if limits.BatchMaxOperations <= 0 {
+		limits.BatchMaxOperations = 500
+	}
+	if limits.ImportMaxRows <= 0 {
+		limits.ImportMaxRows = 10000
+	}
 	return &TodoHandler{
 		service:   s,
 		validator: validator.New(),
+		limits:    limits,
 	}
 }
 
@@ -31,6 +57,8 @@ func NewTodoHandler(s port.TodoService) *TodoHandler {
 // @Accept json
 // @Produce json
 // @Param request body todo.CreateTodoRequest true "Todo creation request"
+// @Param tz query string false "IANA time zone to render times in, defaults to the todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
 // @Success 201 {object} todo.CreateTodoResponse
 // @Failure 400 {object} errors.AppError
 // @Failure 500 {object} errors.AppError
@@ -38,6 +66,11 @@ func NewTodoHandler(s port.TodoService) *TodoHandler {
 func (h *TodoHandler) CreateTodo(c echo.Context) error {
 	log := logger.Get()
 
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
 	var req todo.CreateTodoRequest
 	if err := c.Bind(&req); err != nil {
 		log.Warn("Invalid request body", zap.Error(err))
@@ -56,6 +89,9 @@ func (h *TodoHandler) CreateTodo(c echo.Context) error {
 		ID:          uuid.New().String(),
 		Description: req.Description,
 		DueDate:     req.DueDate,
+		TimeZone:    req.TimeZone,
+		AllDay:      req.AllDay,
+		Recurrence:  req.Recurrence,
 	}
 
 	if err := h.service.CreateTodo(c.Request().Context(), item); err != nil {
@@ -66,7 +102,401 @@ func (h *TodoHandler) CreateTodo(c echo.Context) error {
 
 	log.Info("Todo created successfully", zap.String("todo_id", item.ID))
 
+	setETag(c, item)
 	return c.JSON(201, todo.CreateTodoResponse{
-		TodoItem: *item,
+		TodoItem: render(item, zone),
+	})
+}
+
+// GetTodo returns a todo item
+// @Summary Get a todo item
+// @Tags todos
+// @Produce json
+// @Param id path string true "Todo ID"
+// @Param tz query string false "IANA time zone to render times in, defaults to the todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Success 200 {object} todo.TodoResponse
+// @Success 304 "Cached copy is current"
+// @Failure 400 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id} [get]
+func (h *TodoHandler) GetTodo(c echo.Context) error {
+	id := c.Param("id")
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	item, err := h.service.GetTodo(c.Request().Context(), id)
+	if err != nil {
+		appErr := todoError(err, "failed to get todo")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	setETag(c, item)
+	if notModified(c, item) {
+		return c.NoContent(304)
+	}
+	return c.JSON(200, todo.TodoResponse{
+		TodoItem: render(item, zone),
+	})
+}
+
+// UpdateTodo replaces the editable fields of a todo item
+// @Summary Update a todo item
+// @Description Replace the description, due date and recurrence rule of a todo item; If-Match must carry the ETag the client last read
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Param id path string true "Todo ID"
+// @Param request body todo.UpdateTodoRequest true "Todo update request"
+// @Param If-Match header string true "ETag of the version being updated, or *"
+// @Param tz query string false "IANA time zone to render times in, defaults to the todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Success 200 {object} todo.TodoResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 412 {object} errors.AppError
+// @Failure 428 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id} [put]
+func (h *TodoHandler) UpdateTodo(c echo.Context) error {
+	log := logger.Get()
+	id := c.Param("id")
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	versions, appErr := ifMatch(c, true)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	var req todo.UpdateTodoRequest
+	if err := c.Bind(&req); err != nil {
+		log.Warn("Invalid request body", zap.Error(err))
+		appErr := errors.NewBadRequestError("invalid request body", err)
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	if err := h.validator.Validate(&req); err != nil {
+		log.Warn("Validation failed", zap.Error(err), zap.Any("request", req))
+		appErr := errors.NewValidationError(err.Error())
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	var item *todo.TodoItem
+	err := eachVersion(versions, func(version int) error {
+		item = &todo.TodoItem{
+			ID:          id,
+			Description: req.Description,
+			DueDate:     req.DueDate,
+			TimeZone:    req.TimeZone,
+			AllDay:      req.AllDay,
+			Recurrence:  req.Recurrence,
+			Version:     version,
+		}
+		return h.service.UpdateTodo(c.Request().Context(), item)
+	})
+	if err != nil {
+		appErr := todoError(err, "failed to update todo")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	log.Info("Todo updated successfully", zap.String("todo_id", item.ID))
+
+	setETag(c, item)
+	return c.JSON(200, todo.TodoResponse{
+		TodoItem: render(item, zone),
+	})
+}
+
+// CompleteTodo marks a todo item as completed
+// @Summary Complete a todo item
+// @Description Complete a todo item; for a recurring todo the next occurrence is created and returned as next. If-Match is optional.
+// @Tags todos
+// @Produce json
+// @Param id path string true "Todo ID"
+// @Param tz query string false "IANA time zone to render times in, defaults to the todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Param If-Match header string false "ETag of the version being completed"
+// @Success 200 {object} todo.CompleteTodoResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 409 {object} errors.AppError
+// @Failure 412 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id}/complete [post]
+func (h *TodoHandler) CompleteTodo(c echo.Context) error {
+	log := logger.Get()
+	id := c.Param("id")
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	versions, appErr := ifMatch(c, false)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	var item, next *todo.TodoItem
+	err := eachVersion(versions, func(version int) (err error) {
+		item, next, err = h.service.CompleteTodo(c.Request().Context(), id, version)
+		return err
+	})
+	if err != nil {
+		appErr := todoError(err, "failed to complete todo")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	if next != nil {
+		log.Info("Todo completed, next occurrence created", zap.String("todo_id", item.ID), zap.String("next_id", next.ID))
+	} else {
+		log.Info("Todo completed successfully", zap.String("todo_id", item.ID))
+	}
+
+	setETag(c, item)
+	resp := todo.CompleteTodoResponse{
+		TodoItem: render(item, zone),
+	}
+	if next != nil {
+		rendered := render(next, zone)
+		resp.Next = &rendered
+	}
+	return c.JSON(200, resp)
+}
+
+// DeleteTodo moves a todo item to the trash
+// @Summary Delete a todo item
+// @Description Move a todo item to the trash, from where it can be restored until it is purged; If-Match must carry the ETag the client last read
+// @Tags todos
+// @Param id path string true "Todo ID"
+// @Param If-Match header string true "ETag of the version being deleted, or *"
+// @Success 204 "Deleted"
+// @Failure 404 {object} errors.AppError
+// @Failure 412 {object} errors.AppError
+// @Failure 428 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id} [delete]
+func (h *TodoHandler) DeleteTodo(c echo.Context) error {
+	id := c.Param("id")
+
+	versions, appErr := ifMatch(c, true)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	err := eachVersion(versions, func(version int) error {
+		return h.service.DeleteTodo(c.Request().Context(), id, version)
 	})
+	if err != nil {
+		appErr := todoError(err, "failed to delete todo")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	logger.Get().Info("Todo deleted successfully", zap.String("todo_id", id))
+
+	return c.NoContent(204)
+}
+
+// RestoreTodo takes a todo item out of the trash
+// @Summary Restore a deleted todo item
+// @Description Restore a todo item from the trash as it was when deleted. If-Match is optional.
+// @Tags todos
+// @Produce json
+// @Param id path string true "Todo ID"
+// @Param If-Match header string false "ETag of the deleted version, as listed in the trash"
+// @Param tz query string false "IANA time zone to render times in, defaults to the todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Success 200 {object} todo.TodoResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 412 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id}/restore [post]
+func (h *TodoHandler) RestoreTodo(c echo.Context) error {
+	id := c.Param("id")
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	versions, appErr := ifMatch(c, false)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	var item *todo.TodoItem
+	err := eachVersion(versions, func(version int) (err error) {
+		item, err = h.service.RestoreTodo(c.Request().Context(), id, version)
+		return err
+	})
+	if err != nil {
+		appErr := todoError(err, "failed to restore todo")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	logger.Get().Info("Todo restored successfully", zap.String("todo_id", id))
+
+	setETag(c, item)
+	return c.JSON(200, todo.TodoResponse{
+		TodoItem: render(item, zone),
+	})
+}
+
+// History returns the audit log of a todo item
+// @Summary Get the change history of a todo item
+// @Description List every change made to a todo item, oldest first, with who made it and what changed. The history outlives the todo, purged todos included.
+// @Tags todos
+// @Produce json
+// @Param id path string true "Todo ID"
+// @Param limit query int false "Page size, at most 200" default(50)
+// @Param offset query int false "Number of entries to skip" default(0)
+// @Param tz query string false "IANA time zone to render times in, defaults to UTC"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Success 200 {object} audit.HistoryResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 404 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todo/{id}/history [get]
+func (h *TodoHandler) History(c echo.Context) error {
+	id := c.Param("id")
+
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+
+	limit, offset, appErr := page(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	entries, err := h.service.History(c.Request().Context(), id, limit, offset)
+	if err != nil {
+		appErr := todoError(err, "failed to get todo history")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	return c.JSON(200, audit.NewHistoryResponse(id, entries, limit, offset, zone))
+}
+
+// ListTrash lists deleted todo items
+// @Summary List deleted todo items
+// @Description List the todo items in the trash, most recently deleted first. They are purged once the retention period has passed.
+// @Tags todos
+// @Produce json
+// @Param limit query int false "Page size, at most 200" default(50)
+// @Param offset query int false "Number of items to skip" default(0)
+// @Param tz query string false "IANA time zone to render times in, defaults to each todo's zone"
+// @Param X-Time-Zone header string false "IANA time zone to render times in when tz is not given"
+// @Success 200 {object} todo.TrashResponse
+// @Failure 400 {object} errors.AppError
+// @Failure 500 {object} errors.AppError
+// @Router /todos/trash [get]
+func (h *TodoHandler) ListTrash(c echo.Context) error {
+	zone, appErr := requestedZone(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	limit, offset, appErr := page(c)
+	if appErr != nil {
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	items, err := h.service.ListTrash(c.Request().Context(), limit, offset)
+	if err != nil {
+		appErr := todoError(err, "failed to list trash")
+		return c.JSON(appErr.Code, appErr)
+	}
+
+	resp := todo.TrashResponse{
+		TodoItems: make([]todo.TodoItem, len(items)),
+		Limit:     limit,
+		Offset:    offset,
+	}
+	for i := range items {
+		resp.TodoItems[i] = render(&items[i], zone)
+	}
+	return c.JSON(200, resp)
+}
+
+// Page size bounds of list endpoints
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// page reads the limit and offset query parameters
+func page(c echo.Context) (limit, offset int, appErr *errors.AppError) {
+	limit, offset = defaultPageSize, 0
+	if s := c.QueryParam("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > maxPageSize {
+			return 0, 0, errors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxPageSize))
+		}
+		limit = n
+	}
+	if s := c.QueryParam("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return 0, 0, errors.NewValidationError("offset must not be negative")
+		}
+		offset = n
+	}
+	return limit, offset, nil
+}
+
+// todoError maps service errors to API errors, logging unexpected ones
+func todoError(err error, message string) *errors.AppError {
+	switch {
+	case stderrors.Is(err, todo.ErrNotFound), stderrors.Is(err, todo.ErrNotInTrash):
+		return errors.NewNotFoundError(err.Error())
+	case stderrors.Is(err, todo.ErrAlreadyCompleted):
+		return errors.NewConflictError(err.Error())
+	case stderrors.Is(err, todo.ErrVersionConflict):
+		return errors.NewPreconditionFailedError(err.Error())
+	}
+	logger.Get().Error(message, zap.Error(err))
+	return errors.NewInternalError(message, err)
+}
+
+// requestedZone is the zone responses are rendered in: the tz query
+// parameter, else the X-Time-Zone header carrying the caller's preferred
+// zone. nil leaves every todo in its own zone.
+func requestedZone(c echo.Context) (*time.Location, *errors.AppError) {
+	name := c.QueryParam("tz")
+	if name == "" {
+		name = c.Request().Header.Get("X-Time-Zone")
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.NewBadRequestError("invalid time zone "+name, err)
+	}
+	return loc, nil
+}
+
+// render converts the times of item to zone, or to the todo's own zone
+// when zone is nil
+func render(item *todo.TodoItem, zone *time.Location) todo.TodoItem {
+	if zone == nil {
+		zone = item.Location()
+	}
+	return item.In(zone)
 }
@@ -0,0 +1,80 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"ice/internal/outbox"
+	"ice/internal/port"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type checkerFunc struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string                    { return c.name }
+func (c checkerFunc) Check(ctx context.Context) error { return c.fn(ctx) }
+
+// NewChecker adapts a plain function to the Checker interface
+func NewChecker(name string, fn func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, fn: fn}
+}
+
+// MySQL pings the primary connection pool
+func MySQL(db *sql.DB) Checker {
+	return NewChecker("mysql", db.PingContext)
+}
+
+// Redis pings the redis deployment
+func Redis(client redis.UniversalClient) Checker {
+	return NewChecker("redis", func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	})
+}
+
+// Migration fails while the schema is dirty or behind the version the
+// binary was built for
+func Migration(db *sql.DB, expected uint) Checker {
+	return NewChecker("migration", func(ctx context.Context) error {
+		var (
+			version uint
+			dirty   bool
+		)
+		err := db.QueryRowContext(ctx,
+			`SELECT version, dirty FROM schema_migrations LIMIT 1`,
+		).Scan(&version, &dirty)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no migration applied, expected version %d", expected)
+		}
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("schema version %d is dirty", version)
+		}
+		if version < expected {
+			return fmt.Errorf("schema version %d is behind expected version %d", version, expected)
+		}
+		return nil
+	})
+}
+
+// Outbox fails while the backlog exceeds any of the thresholds
+func Outbox(monitor port.OutboxMonitor, thresholds outbox.Thresholds) Checker {
+	return NewChecker("outbox", func(ctx context.Context) error {
+		stats, err := monitor.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		if problems := thresholds.Problems(stats); len(problems) > 0 {
+			return errors.New(strings.Join(problems, "; "))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,168 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Probe identifies one of the orchestrator facing endpoints
+type Probe string
+
+const (
+	Liveness  Probe = "livez"
+	Readiness Probe = "readyz"
+	Startup   Probe = "startupz"
+)
+
+// Result and report statuses
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusFailed      = "failed"
+	StatusUnavailable = "unavailable"
+)
+
+// Checker reports the health of a single dependency
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// Options controls how a checker takes part in a probe
+type Options struct {
+	// Critical checks fail the probe, others only degrade it
+	Critical bool
+	// Timeout bounds a single run, zero uses the registry default
+	Timeout time.Duration
+}
+
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	Latency   string    `json:"latency"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks,omitempty"`
+}
+
+type registration struct {
+	checker Checker
+	opts    Options
+	probes  map[Probe]bool
+
+	mu     sync.Mutex
+	result Result
+	err    error
+	at     time.Time
+}
+
+// Registry runs registered checkers per probe, caching each result for
+// cacheTTL so frequent probes don't hammer the dependencies
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mu     sync.RWMutex
+	checks []*registration
+}
+
+func NewRegistry(timeout, cacheTTL time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = 2 * time.Second
+	}
+	return &Registry{timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Register adds c to the given probes
+func (r *Registry) Register(c Checker, opts Options, probes ...Probe) {
+	reg := &registration{checker: c, opts: opts, probes: make(map[Probe]bool)}
+	for _, p := range probes {
+		reg.probes[p] = true
+	}
+
+	r.mu.Lock()
+	r.checks = append(r.checks, reg)
+	r.mu.Unlock()
+}
+
+// Run executes every checker registered for probe concurrently. The
+// report is unavailable when a critical check fails and degraded when
+// only non-critical checks do.
+func (r *Registry) Run(ctx context.Context, probe Probe) Report {
+	r.mu.RLock()
+	var regs []*registration
+	for _, reg := range r.checks {
+		if reg.probes[probe] {
+			regs = append(regs, reg)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(regs))
+	var wg sync.WaitGroup
+	for i, reg := range regs {
+		wg.Add(1)
+		go func(i int, reg *registration) {
+			defer wg.Done()
+			results[i] = r.run(ctx, reg)
+		}(i, reg)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, res := range results {
+		switch {
+		case res.Status == StatusFailed:
+			report.Status = StatusUnavailable
+		case res.Status == StatusDegraded && report.Status == StatusOK:
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, reg *registration) Result {
+	// holding the lock while checking makes concurrent probes wait for
+	// the in-flight run instead of starting their own
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if !reg.at.IsZero() && time.Since(reg.at) < r.cacheTTL {
+		return reg.result
+	}
+
+	timeout := reg.opts.Timeout
+	if timeout <= 0 {
+		timeout = r.timeout
+	}
+	// a client hanging up must not cache a failure for everybody else
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := reg.checker.Check(ctx)
+
+	res := Result{
+		Name:      reg.checker.Name(),
+		Status:    StatusOK,
+		Critical:  reg.opts.Critical,
+		Latency:   time.Since(start).String(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		res.Error = err.Error()
+		res.Status = StatusDegraded
+		if reg.opts.Critical {
+			res.Status = StatusFailed
+		}
+	}
+
+	reg.result, reg.at = res, time.Now()
+	return res
+}
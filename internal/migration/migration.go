@@ -0,0 +1,10 @@
+// Package migration holds the SQL migrations, embedded so the binary can
+// migrate from any working directory
+package migration
+
+import "embed"
+
+// MySQL holds the MySQL migrations under mysql/
+//
+//go:embed mysql/*.sql
+var MySQL embed.FS
@@ -0,0 +1,5 @@
+ALTER TABLE outbox
+    DROP INDEX idx_aggregate_status,
+    DROP COLUMN next_attempt_at,
+    DROP COLUMN attempts,
+    DROP COLUMN aggregate_id;
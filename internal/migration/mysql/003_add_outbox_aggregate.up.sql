@@ -0,0 +1,5 @@
+ALTER TABLE outbox
+    ADD COLUMN aggregate_id VARCHAR(64) NOT NULL DEFAULT '' AFTER topic,
+    ADD COLUMN attempts INT UNSIGNED NOT NULL DEFAULT 0 AFTER status,
+    ADD COLUMN next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER attempts,
+    ADD INDEX idx_aggregate_status (aggregate_id, status, id);
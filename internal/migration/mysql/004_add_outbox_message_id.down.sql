@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    DROP INDEX idx_message_id,
+    DROP COLUMN message_id;
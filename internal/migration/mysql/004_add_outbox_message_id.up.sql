@@ -0,0 +1,5 @@
+ALTER TABLE outbox ADD COLUMN message_id CHAR(36) NULL AFTER id;
+UPDATE outbox SET message_id = UUID() WHERE message_id IS NULL;
+ALTER TABLE outbox
+    MODIFY message_id CHAR(36) NOT NULL,
+    ADD UNIQUE INDEX idx_message_id (message_id);
@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    DROP COLUMN content_type,
+    MODIFY payload TEXT NOT NULL;
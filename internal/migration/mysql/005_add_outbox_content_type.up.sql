@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    MODIFY payload MEDIUMBLOB NOT NULL,
+    ADD COLUMN content_type VARCHAR(100) NOT NULL DEFAULT 'application/json' AFTER payload;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    ADD COLUMN event_type VARCHAR(100) NOT NULL DEFAULT '' AFTER aggregate_id,
+    ADD COLUMN schema_version INT UNSIGNED NOT NULL DEFAULT 0 AFTER event_type;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS todo_reminders (
+    todo_id VARCHAR(64) NOT NULL,
+    reminder_window VARCHAR(32) NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (todo_id, reminder_window)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE todos
+    DROP COLUMN completed_at,
+    DROP COLUMN completed,
+    DROP COLUMN occurrence,
+    DROP COLUMN recurrence;
@@ -0,0 +1,5 @@
+ALTER TABLE todos
+    ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '' AFTER due_date,
+    ADD COLUMN occurrence INT UNSIGNED NOT NULL DEFAULT 1 AFTER recurrence,
+    ADD COLUMN completed BOOLEAN NOT NULL DEFAULT FALSE AFTER occurrence,
+    ADD COLUMN completed_at DATETIME NULL AFTER completed;
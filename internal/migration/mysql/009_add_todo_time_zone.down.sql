@@ -0,0 +1,3 @@
+ALTER TABLE todos
+    DROP COLUMN all_day,
+    DROP COLUMN time_zone;
@@ -0,0 +1,3 @@
+ALTER TABLE todos
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT '' AFTER due_date,
+    ADD COLUMN all_day BOOLEAN NOT NULL DEFAULT FALSE AFTER time_zone;
@@ -0,0 +1,10 @@
+-- the character set conversion is kept, it is the server default on MySQL 8
+ALTER TABLE todos
+    DROP INDEX idx_todos_open_due_date,
+    DROP INDEX idx_todos_due_date,
+    DROP CHECK chk_todos_description,
+    DROP COLUMN updated_at,
+    DROP COLUMN created_at,
+    DROP COLUMN version,
+    MODIFY COLUMN due_date DATETIME NULL,
+    MODIFY COLUMN description TEXT NULL;
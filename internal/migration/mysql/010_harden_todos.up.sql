@@ -0,0 +1,22 @@
+-- rows written before the API validated input
+UPDATE todos SET description = '(no description)' WHERE description IS NULL OR description = '';
+-- a todo without a due date gets one to satisfy NOT NULL, but it is closed
+-- as completed at the same time: an open todo suddenly due now would be
+-- picked up by the reminder scheduler as overdue
+UPDATE todos
+SET due_date = UTC_TIMESTAMP(), completed = TRUE, completed_at = COALESCE(completed_at, UTC_TIMESTAMP())
+WHERE due_date IS NULL;
+
+-- todo_reminders joins todos on the id, both use the same collation
+ALTER TABLE todos CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+ALTER TABLE todo_reminders CONVERT TO CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci;
+
+ALTER TABLE todos
+    MODIFY COLUMN description TEXT NOT NULL,
+    MODIFY COLUMN due_date DATETIME NOT NULL,
+    ADD COLUMN version INT UNSIGNED NOT NULL DEFAULT 1 AFTER completed_at,
+    ADD COLUMN created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER version,
+    ADD COLUMN updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP AFTER created_at,
+    ADD CONSTRAINT chk_todos_description CHECK (CHAR_LENGTH(description) > 0),
+    ADD INDEX idx_todos_due_date (due_date),
+    ADD INDEX idx_todos_open_due_date (completed, due_date);
@@ -0,0 +1,5 @@
+-- todos in the trash would come back to life
+DELETE FROM todos WHERE deleted_at IS NOT NULL;
+ALTER TABLE todos
+    DROP INDEX idx_todos_deleted_at,
+    DROP COLUMN deleted_at;
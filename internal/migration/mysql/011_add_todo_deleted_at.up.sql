@@ -0,0 +1,3 @@
+ALTER TABLE todos
+    ADD COLUMN deleted_at DATETIME NULL AFTER updated_at,
+    ADD INDEX idx_todos_deleted_at (deleted_at);
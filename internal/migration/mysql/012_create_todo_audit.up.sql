@@ -0,0 +1,21 @@
+-- no foreign key: the history of a todo outlives its purge
+CREATE TABLE IF NOT EXISTS todo_audit (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    todo_id VARCHAR(64) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    version INT UNSIGNED NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    client_ip VARCHAR(45) NOT NULL DEFAULT '',
+    changes JSON NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    INDEX idx_todo_audit_todo (todo_id, id)
+) DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
+
+-- the log is append-only, rows can neither be changed nor removed; with
+-- binary logging on, creating triggers needs SUPER or
+-- log_bin_trust_function_creators=1 besides the TRIGGER privilege
+CREATE TRIGGER todo_audit_no_update BEFORE UPDATE ON todo_audit
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'todo_audit is append-only';
+CREATE TRIGGER todo_audit_no_delete BEFORE DELETE ON todo_audit
+    FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'todo_audit is append-only';
@@ -0,0 +1,3 @@
+ALTER TABLE todos
+    DROP INDEX uq_todos_external_id,
+    DROP COLUMN external_id;
@@ -0,0 +1,4 @@
+-- identifies todos imported from other tools, NULL for the others
+ALTER TABLE todos
+    ADD COLUMN external_id VARCHAR(255) NULL AFTER id,
+    ADD UNIQUE INDEX uq_todos_external_id (external_id);
@@ -0,0 +1,7 @@
+-- one calendar feed token per owner; only the SHA-256 of its secret is kept
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    owner VARCHAR(255) NOT NULL PRIMARY KEY,
+    token_hash BINARY(32) NOT NULL,
+    created_at DATETIME NOT NULL,
+    UNIQUE INDEX uq_calendar_tokens_hash (token_hash)
+) DEFAULT CHARSET = utf8mb4 COLLATE = utf8mb4_0900_ai_ci;
@@ -0,0 +1,3 @@
+ALTER TABLE outbox
+    ADD INDEX idx_status (status),
+    DROP INDEX idx_status_updated_at;
@@ -0,0 +1,5 @@
+-- lets the health check read the last publish time off the index instead
+-- of scanning every sent row
+ALTER TABLE outbox
+    ADD INDEX idx_status_updated_at (status, updated_at),
+    DROP INDEX idx_status;
@@ -0,0 +1,27 @@
+package outbox
+
+import "time"
+
+type StatusResponse struct {
+	Status               string     `json:"status" example:"ok"`
+	Pending              int64      `json:"pending" example:"3"`
+	Failed               int64      `json:"failed" example:"0"`
+	OldestPendingSeconds float64    `json:"oldestPendingSeconds" example:"1.5"`
+	LastPublishedAt      *time.Time `json:"lastPublishedAt" example:"2025-01-01T06:00:00Z"`
+	Problems             []string   `json:"problems,omitempty"`
+}
+
+func NewStatusResponse(stats Stats, problems []string) StatusResponse {
+	status := "ok"
+	if len(problems) > 0 {
+		status = "degraded"
+	}
+	return StatusResponse{
+		Status:               status,
+		Pending:              stats.Pending,
+		Failed:               stats.Failed,
+		OldestPendingSeconds: stats.OldestPendingAge.Seconds(),
+		LastPublishedAt:      stats.LastPublishedAt,
+		Problems:             problems,
+	}
+}
@@ -2,11 +2,42 @@ package outbox
 
 import "time"
 
+// Headers published next to every payload; with binary encodings the
+// event type and schema version tell consumers which schema to decode with
+const (
+	HeaderContentType   = "content_type"
+	HeaderAggregateID   = "aggregate_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+)
+
 type OutboxItem struct {
-	ID        int64
+	ID int64
+	// MessageID is a stable UUID published with the entry so consumers
+	// can recognise a message delivered twice
+	MessageID string
 	Topic     string
-	Payload   string
-	Status    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	// AggregateID identifies the entity the event belongs to, e.g. the
+	// todo ID; events of one aggregate are published in order
+	AggregateID   string
+	EventType     string
+	SchemaVersion int
+	// Payload holds the encoded event exactly as it will be published
+	Payload       []byte
+	ContentType   string
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// PartitionKey groups messages that must be published in order; messages
+// with different keys may be published concurrently. Rows written before
+// aggregates were tracked fall back to per-topic ordering.
+func (m OutboxItem) PartitionKey() string {
+	if m.AggregateID != "" {
+		return m.AggregateID
+	}
+	return m.Topic
 }
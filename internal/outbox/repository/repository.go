@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"database/sql"
 	"ice/internal/adapter/mysql"
 	"ice/internal/outbox"
+	"strings"
+	"time"
 )
 
 type Repository struct {
@@ -15,19 +18,62 @@ func NewRepository(db *mysql.MySQL) *Repository {
 }
 
 func (r *Repository) Insert(ctx context.Context, msg *outbox.OutboxItem) error {
-	_, err := r.db.DB().ExecContext(ctx,
-		`INSERT INTO outbox (topic, payload, status)
-		 VALUES (?, ?, 'pending')`,
-		msg.Topic, msg.Payload,
+	_, err := r.db.Writer(ctx).ExecContext(ctx,
+		`INSERT INTO outbox (message_id, topic, aggregate_id, event_type, schema_version, payload, content_type, status)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, 'pending')`,
+		msg.MessageID, msg.Topic, msg.AggregateID, msg.EventType, msg.SchemaVersion, msg.Payload, msg.ContentType,
 	)
 	return err
 }
 
+// insertChunk bounds the rows per INSERT, keeping statements well below
+// the placeholder limit and max_allowed_packet
+const insertChunk = 200
+
+// InsertBatch inserts msgs in order with multi-row inserts; their ids
+// grow in the same order, which keeps the events of an aggregate ordered
+func (r *Repository) InsertBatch(ctx context.Context, msgs []*outbox.OutboxItem) error {
+	for start := 0; start < len(msgs); start += insertChunk {
+		chunk := msgs[start:min(start+insertChunk, len(msgs))]
+
+		values := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*7)
+		for i, msg := range chunk {
+			values[i] = "(?, ?, ?, ?, ?, ?, ?, 'pending')"
+			args = append(args, msg.MessageID, msg.Topic, msg.AggregateID, msg.EventType, msg.SchemaVersion, msg.Payload, msg.ContentType)
+		}
+
+		_, err := r.db.Writer(ctx).ExecContext(ctx,
+			`INSERT INTO outbox (message_id, topic, aggregate_id, event_type, schema_version, payload, content_type, status)
+			 VALUES `+strings.Join(values, ", "),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FetchPending returns due pending messages in id order, leaving out any
+// message queued behind an earlier message of the same aggregate that is
+// waiting for a retry, so an aggregate's events are never reordered.
+//
+// It always reads the primary: a lagging replica would hand out rows that
+// were already marked as sent. Rows are not claimed, callers must make
+// sure a single processor fetches at a time.
 func (r *Repository) FetchPending(ctx context.Context, limit int) ([]outbox.OutboxItem, error) {
-	rows, err := r.db.DB().QueryContext(ctx,
-		`SELECT id, topic, payload FROM outbox
-		 WHERE status='pending'
-		 ORDER BY id ASC
+	rows, err := r.db.Writer(ctx).QueryContext(ctx,
+		`SELECT o.id, o.message_id, o.topic, o.aggregate_id, o.event_type, o.schema_version,
+		   o.payload, o.content_type, o.attempts
+		 FROM outbox o
+		 WHERE o.status='pending' AND o.next_attempt_at <= NOW()
+		   AND NOT EXISTS (
+		     SELECT 1 FROM outbox p
+		     WHERE o.aggregate_id <> '' AND p.aggregate_id = o.aggregate_id
+		       AND p.status='pending' AND p.id < o.id AND p.next_attempt_at > NOW()
+		   )
+		 ORDER BY o.id ASC
 		 LIMIT ?`, limit)
 	if err != nil {
 		return nil, err
@@ -37,22 +83,99 @@ func (r *Repository) FetchPending(ctx context.Context, limit int) ([]outbox.Outb
 	var list []outbox.OutboxItem
 	for rows.Next() {
 		var m outbox.OutboxItem
-		rows.Scan(&m.ID, &m.Topic, &m.Payload)
+		if err := rows.Scan(&m.ID, &m.MessageID, &m.Topic, &m.AggregateID, &m.EventType, &m.SchemaVersion,
+			&m.Payload, &m.ContentType, &m.Attempts); err != nil {
+			return nil, err
+		}
 		list = append(list, m)
 	}
-	return list, nil
+	return list, rows.Err()
+}
+
+func (r *Repository) MarkSent(ctx context.Context, ids []int64) error {
+	return r.setStatus(ctx, "sent", ids)
 }
 
-func (r *Repository) MarkSent(ctx context.Context, id int64) error {
-	_, err := r.db.DB().ExecContext(ctx,
-		`UPDATE outbox SET status='sent' WHERE id=?`,
-		id)
+// RecordFailures counts a failed publish attempt for ids. Messages stay
+// pending with an exponential backoff capped at maxBackoff and are
+// dead-lettered as failed once they reach maxAttempts.
+func (r *Repository) RecordFailures(ctx context.Context, ids []int64, maxAttempts int, maxBackoff time.Duration) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(ids)+2)
+	args = append(args, maxAttempts, int64(maxBackoff/time.Second))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	// assignments are evaluated left to right, status and backoff see the
+	// incremented attempts
+	_, err := r.db.Writer(ctx).ExecContext(ctx,
+		`UPDATE outbox SET
+		   attempts = attempts + 1,
+		   status = IF(attempts >= ?, 'failed', 'pending'),
+		   next_attempt_at = NOW() + INTERVAL LEAST(POW(2, attempts), ?) SECOND
+		 WHERE id IN (`+placeholders(len(ids))+`)`,
+		args...)
 	return err
 }
 
-func (r *Repository) MarkFailed(ctx context.Context, id int64) error {
-	_, err := r.db.DB().ExecContext(ctx,
-		`UPDATE outbox SET status='failed' WHERE id=?`,
-		id)
+// setStatus updates all ids with a single statement
+func (r *Repository) setStatus(ctx context.Context, status string, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := make([]any, 0, len(ids)+1)
+	args = append(args, status)
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	_, err := r.db.Writer(ctx).ExecContext(ctx,
+		`UPDATE outbox SET status=? WHERE id IN (`+placeholders(len(ids))+`)`,
+		args...)
 	return err
 }
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+// Stats computes ages with the database clock so they are not affected
+// by the session time zone or clock skew on this host
+func (r *Repository) Stats(ctx context.Context) (outbox.Stats, error) {
+	var (
+		stats   outbox.Stats
+		seconds sql.NullInt64
+		last    sql.NullTime
+	)
+
+	err := r.db.Writer(ctx).QueryRowContext(ctx,
+		`SELECT
+		   COALESCE(SUM(status='pending'), 0),
+		   COALESCE(SUM(status='failed'), 0),
+		   TIMESTAMPDIFF(SECOND, MIN(CASE WHEN status='pending' THEN created_at END), NOW())
+		 FROM outbox WHERE status IN ('pending','failed')`,
+	).Scan(&stats.Pending, &stats.Failed, &seconds)
+	if err != nil {
+		return stats, err
+	}
+	if seconds.Valid {
+		stats.OldestPendingAge = time.Duration(seconds.Int64) * time.Second
+	}
+
+	// resolved from the end of idx_status_updated_at without a scan
+	err = r.db.Writer(ctx).QueryRowContext(ctx,
+		`SELECT MAX(updated_at) FROM outbox WHERE status='sent'`,
+	).Scan(&last)
+	if err != nil {
+		return stats, err
+	}
+	if last.Valid {
+		stats.LastPublishedAt = &last.Time
+	}
+	return stats, nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"ice/internal/outbox"
+	"ice/internal/port"
+	"ice/pkg/logger"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// lockName is the MySQL lock electing the instance that publishes
+const lockName = "ice.outbox.processor"
+
+// bookkeepingTimeout bounds status updates, which run on a context
+// detached from the processor so they complete during shutdown
+const bookkeepingTimeout = 5 * time.Second
+
+// Processor is the handle of a running outbox processor
+type Processor struct {
+	cancel context.CancelFunc
+	// abort cancels the publish of the in-flight batch
+	abort context.CancelFunc
+	done  chan struct{}
+}
+
+// StartProcessor publishes pending messages in the background until ctx
+// is cancelled or Stop is called. It drains as soon as Write signals a
+// commit; otherwise it polls, backing off from PollMinInterval up to
+// PollMaxInterval while the outbox stays empty, and fetches again without
+// waiting as long as batches come back full.
+func (s *Service) StartProcessor(ctx context.Context) *Processor {
+	ctx, cancel := context.WithCancel(ctx)
+	// a fetched batch is published even while the processor is being
+	// stopped, unless Stop runs out of time and aborts it
+	publishCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	p := &Processor{cancel: cancel, abort: abort, done: make(chan struct{})}
+
+	var nudges <-chan struct{}
+	if s.notifier != nil {
+		nudges = s.notifier.Subscribe(ctx)
+	}
+
+	go func() {
+		defer close(p.done)
+		defer abort()
+
+		interval := s.cfg.PollMinInterval
+		timer := time.NewTimer(interval)
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Get().Info("Outbox processor stopped")
+				return
+
+			case <-s.wake:
+			case <-nudges:
+			case <-timer.C:
+			}
+
+			if s.drain(ctx, publishCtx) {
+				interval = s.cfg.PollMinInterval
+			} else {
+				interval = min(interval*2, s.cfg.PollMaxInterval)
+			}
+			timer.Reset(interval)
+		}
+	}()
+
+	return p
+}
+
+// drain processes batches until one comes back short and reports whether
+// any message was found. Only one instance drains at a time: two
+// processors fetching the same pending rows would publish them twice and
+// could reorder an aggregate's events. The others skip the round and find
+// the outbox empty.
+func (s *Service) drain(ctx, publishCtx context.Context) bool {
+	release, ok, err := s.locker.Lock(ctx, lockName, 0)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().Error("failed to acquire outbox processor lock", zap.Error(err))
+		}
+		return false
+	}
+	if !ok {
+		return false
+	}
+	defer release()
+
+	found := false
+	for ctx.Err() == nil {
+		n := s.process(ctx, publishCtx)
+		found = found || n > 0
+		if n < s.cfg.BatchSize {
+			break
+		}
+	}
+	return found
+}
+
+// Stop prevents new batches from starting and waits for the in-flight one
+// to finish. If ctx expires first the batch's publish is aborted and Stop
+// still waits for its status updates, at most bookkeepingTimeout, so the
+// batch never outlives the adapters closed after the processor; it then
+// returns ctx.Err().
+func (p *Processor) Stop(ctx context.Context) error {
+	p.cancel()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.abort()
+	<-p.done
+	return ctx.Err()
+}
+
+// process publishes one batch with publishCtx and returns the number of
+// messages fetched
+func (s *Service) process(ctx, publishCtx context.Context) int {
+	msgs, err := s.repo.FetchPending(ctx, s.cfg.BatchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Get().Error("failed to fetch pending outbox", zap.Error(err))
+		}
+		return 0
+	}
+
+	parts := s.partition(msgs)
+	results := make([]publishResult, len(parts))
+
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.publish(publishCtx, part)
+		}()
+	}
+	wg.Wait()
+
+	var sent, failed []int64
+	for _, res := range results {
+		sent = append(sent, res.sent...)
+		failed = append(failed, res.failed...)
+	}
+
+	// the statuses are recorded even if the processor is being stopped,
+	// otherwise published messages would stay pending and be sent again
+	// by the next run
+	ctx = context.WithoutCancel(ctx)
+	s.markSent(ctx, sent)
+	if publishCtx.Err() == nil {
+		// an aborted publish is not the message's fault, it stays pending
+		// without using up an attempt
+		s.recordFailures(ctx, failed)
+	}
+
+	return len(msgs)
+}
+
+type publishResult struct {
+	sent   []int64
+	failed []int64
+}
+
+// publish appends a partition with a single round-trip. A partition
+// targets one stream, so on failure the whole partition is retried: no
+// message overtakes an earlier one of its aggregate, while those appended
+// before the failure are published again with their message ID.
+func (s *Service) publish(ctx context.Context, msgs []outbox.OutboxItem) publishResult {
+	batch := make([]port.StreamMessage, len(msgs))
+	ids := make([]int64, len(msgs))
+	for i, msg := range msgs {
+		batch[i] = port.StreamMessage{
+			ID:      msg.MessageID,
+			Stream:  msg.Topic,
+			Payload: msg.Payload,
+			Headers: map[string]string{
+				outbox.HeaderContentType:   msg.ContentType,
+				outbox.HeaderAggregateID:   msg.AggregateID,
+				outbox.HeaderEventType:     msg.EventType,
+				outbox.HeaderSchemaVersion: strconv.Itoa(msg.SchemaVersion),
+			},
+		}
+		ids[i] = msg.ID
+	}
+
+	if err := s.publisher.PublishBatch(ctx, batch); err != nil {
+		logger.Get().Error("failed to publish", zap.Int64s("ids", ids), zap.Error(err))
+		return publishResult{failed: ids}
+	}
+	return publishResult{sent: ids}
+}
+
+type partitionID struct {
+	topic  string
+	worker int
+}
+
+// partition splits a batch across the workers of each topic. A partition
+// key always lands on the same worker and messages keep their fetch order
+// within a worker, so ordering holds per key.
+func (s *Service) partition(msgs []outbox.OutboxItem) [][]outbox.OutboxItem {
+	index := make(map[partitionID]int)
+	var parts [][]outbox.OutboxItem
+
+	for _, msg := range msgs {
+		id := partitionID{topic: msg.Topic}
+		if workers := s.workers(msg.Topic); workers > 1 {
+			h := fnv.New32a()
+			h.Write([]byte(msg.PartitionKey()))
+			id.worker = int(h.Sum32() % uint32(workers))
+		}
+
+		i, ok := index[id]
+		if !ok {
+			i = len(parts)
+			index[id] = i
+			parts = append(parts, nil)
+		}
+		parts[i] = append(parts[i], msg)
+	}
+	return parts
+}
+
+func (s *Service) workers(topic string) int {
+	if n, ok := s.cfg.TopicWorkers[topic]; ok {
+		return n
+	}
+	return s.cfg.Workers
+}
+
+func (s *Service) markSent(ctx context.Context, ids []int64) {
+	ctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+	defer cancel()
+
+	if err := s.repo.MarkSent(ctx, ids); err != nil {
+		logger.Get().Error("failed to mark outbox messages as sent", zap.Int64s("ids", ids), zap.Error(err))
+	}
+}
+
+func (s *Service) recordFailures(ctx context.Context, ids []int64) {
+	ctx, cancel := context.WithTimeout(ctx, bookkeepingTimeout)
+	defer cancel()
+
+	if err := s.repo.RecordFailures(ctx, ids, s.cfg.MaxAttempts, s.cfg.MaxBackoff); err != nil {
+		logger.Get().Error("failed to record outbox publish failures", zap.Int64s("ids", ids), zap.Error(err))
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"ice/config"
+	"ice/internal/event"
+	"ice/internal/outbox"
+	"ice/internal/port"
+)
+
+// roundTrip simulates the network latency of a single redis or mysql call
+const roundTrip = 100 * time.Microsecond
+
+type benchRepo struct {
+	batch []outbox.OutboxItem
+}
+
+func (r *benchRepo) Insert(context.Context, *outbox.OutboxItem) error { return nil }
+
+func (r *benchRepo) InsertBatch(context.Context, []*outbox.OutboxItem) error { return nil }
+
+func (r *benchRepo) FetchPending(context.Context, int) ([]outbox.OutboxItem, error) {
+	time.Sleep(roundTrip)
+	return r.batch, nil
+}
+
+func (r *benchRepo) MarkSent(context.Context, []int64) error {
+	time.Sleep(roundTrip)
+	return nil
+}
+
+func (r *benchRepo) RecordFailures(context.Context, []int64, int, time.Duration) error {
+	time.Sleep(roundTrip)
+	return nil
+}
+
+func (r *benchRepo) Stats(context.Context) (outbox.Stats, error) { return outbox.Stats{}, nil }
+
+type benchPublisher struct{}
+
+func (benchPublisher) Publish(context.Context, port.StreamMessage) error {
+	time.Sleep(roundTrip)
+	return nil
+}
+
+func (benchPublisher) PublishBatch(context.Context, []port.StreamMessage) error {
+	time.Sleep(roundTrip)
+	return nil
+}
+
+type benchTx struct{}
+
+func (benchTx) WithTx(ctx context.Context, fn func(ctx context.Context) error) error { return fn(ctx) }
+func (benchTx) AfterCommit(_ context.Context, fn func())                             { fn() }
+
+func benchBatch(size, topics, aggregates int) []outbox.OutboxItem {
+	batch := make([]outbox.OutboxItem, size)
+	for i := range batch {
+		batch[i] = outbox.OutboxItem{
+			ID:            int64(i + 1),
+			Topic:         fmt.Sprintf("topic_%d", i%topics),
+			AggregateID:   fmt.Sprintf("aggregate_%d", i%aggregates),
+			Payload:       []byte(`{"type":"todo_created","schema_version":1,"id":"4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10","description":"test task","dueDate":"2025-01-01T06:00:00Z"}`),
+			EventType:     event.TypeTodoCreated,
+			SchemaVersion: 1,
+			ContentType:   event.ContentTypeJSON,
+		}
+	}
+	return batch
+}
+
+func BenchmarkProcess(b *testing.B) {
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			repo := &benchRepo{batch: benchBatch(100, 2, 16)}
+			s := NewService(Dependencies{Repo: repo, Publisher: benchPublisher{}, Tx: benchTx{}}, config.OutboxConfig{
+				BatchSize: 100,
+				Workers:   workers,
+			})
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				s.process(context.Background(), context.Background())
+			}
+		})
+	}
+}
+
+func BenchmarkPartition(b *testing.B) {
+	s := NewService(Dependencies{Repo: &benchRepo{}, Publisher: benchPublisher{}, Tx: benchTx{}}, config.OutboxConfig{Workers: 8})
+	batch := benchBatch(100, 2, 16)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.partition(batch)
+	}
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"ice/config"
+	"ice/internal/outbox"
+	"ice/internal/port"
+)
+
+func TestPartition(t *testing.T) {
+	tests := []struct {
+		name      string
+		workers   int
+		batch     []outbox.OutboxItem
+		wantParts int
+	}{
+		{
+			name:      "single worker keeps a topic together",
+			workers:   1,
+			batch:     benchBatch(100, 2, 16),
+			wantParts: 2,
+		},
+		{
+			name:      "aggregates spread across workers",
+			workers:   4,
+			batch:     benchBatch(100, 1, 16),
+			wantParts: 4,
+		},
+		{
+			name:      "one aggregate stays on one worker",
+			workers:   4,
+			batch:     benchBatch(100, 1, 1),
+			wantParts: 1,
+		},
+		{
+			name:    "rows without aggregate stay on one worker",
+			workers: 4,
+			batch: []outbox.OutboxItem{
+				{ID: 1, Topic: "todo_stream"},
+				{ID: 2, Topic: "todo_stream"},
+				{ID: 3, Topic: "todo_stream"},
+			},
+			wantParts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewService(Dependencies{Repo: &benchRepo{}, Publisher: benchPublisher{}, Tx: benchTx{}}, config.OutboxConfig{Workers: tt.workers})
+			parts := s.partition(tt.batch)
+
+			if len(parts) != tt.wantParts {
+				t.Fatalf("got %d partitions, want %d", len(parts), tt.wantParts)
+			}
+
+			owner := make(map[string]int)
+			total := 0
+			for i, part := range parts {
+				total += len(part)
+				for j, msg := range part {
+					key := msg.Topic + "/" + msg.PartitionKey()
+					if p, ok := owner[key]; ok && p != i {
+						t.Errorf("key %s split across partitions %d and %d", key, p, i)
+					}
+					owner[key] = i
+					if j > 0 && part[j-1].ID >= msg.ID {
+						t.Errorf("partition %d out of fetch order at id %d", i, msg.ID)
+					}
+				}
+			}
+			if total != len(tt.batch) {
+				t.Errorf("got %d messages, want %d", total, len(tt.batch))
+			}
+		})
+	}
+}
+
+type fakeLocker struct {
+	ok  bool
+	err error
+}
+
+func (l fakeLocker) Lock(context.Context, string, time.Duration) (func(), bool, error) {
+	return func() {}, l.ok, l.err
+}
+
+type countingRepo struct {
+	benchRepo
+	fetches int
+}
+
+func (r *countingRepo) FetchPending(ctx context.Context, limit int) ([]outbox.OutboxItem, error) {
+	r.fetches++
+	return r.benchRepo.FetchPending(ctx, limit)
+}
+
+func TestDrainOnlyWithLock(t *testing.T) {
+	tests := []struct {
+		name        string
+		locker      fakeLocker
+		wantFound   bool
+		wantFetches int
+	}{
+		{name: "lock held here", locker: fakeLocker{ok: true}, wantFound: true, wantFetches: 1},
+		{name: "lock held elsewhere", locker: fakeLocker{ok: false}},
+		{name: "lock error", locker: fakeLocker{err: errors.New("connection refused")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &countingRepo{benchRepo: benchRepo{batch: benchBatch(3, 1, 1)}}
+			s := NewService(Dependencies{Repo: repo, Publisher: benchPublisher{}, Tx: benchTx{}, Locker: tt.locker}, config.OutboxConfig{BatchSize: 10})
+
+			ctx := context.Background()
+			if found := s.drain(ctx, ctx); found != tt.wantFound {
+				t.Errorf("drain() = %v, want %v", found, tt.wantFound)
+			}
+			if repo.fetches != tt.wantFetches {
+				t.Errorf("fetched %d times, want %d", repo.fetches, tt.wantFetches)
+			}
+		})
+	}
+}
+
+// stopRepo hands out its batch once and records the status updates along
+// with the state of their context
+type stopRepo struct {
+	benchRepo
+	fetched bool
+	sent    []int64
+	failed  []int64
+	ctxErrs []error
+}
+
+func (r *stopRepo) FetchPending(context.Context, int) ([]outbox.OutboxItem, error) {
+	if r.fetched {
+		return nil, nil
+	}
+	r.fetched = true
+	return r.batch, nil
+}
+
+func (r *stopRepo) MarkSent(ctx context.Context, ids []int64) error {
+	if len(ids) > 0 {
+		r.sent = append(r.sent, ids...)
+		r.ctxErrs = append(r.ctxErrs, ctx.Err())
+	}
+	return nil
+}
+
+func (r *stopRepo) RecordFailures(ctx context.Context, ids []int64, _ int, _ time.Duration) error {
+	if len(ids) > 0 {
+		r.failed = append(r.failed, ids...)
+		r.ctxErrs = append(r.ctxErrs, ctx.Err())
+	}
+	return nil
+}
+
+// blockingPublisher holds a publish until release is closed or its
+// context is cancelled
+type blockingPublisher struct {
+	benchPublisher
+	started chan struct{}
+	release chan struct{}
+	err     error
+}
+
+func (p blockingPublisher) PublishBatch(ctx context.Context, _ []port.StreamMessage) error {
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestProcessorStop(t *testing.T) {
+	tests := []struct {
+		name string
+		// release lets the blocked publish finish once Stop is waiting
+		release    bool
+		publishErr error
+		timeout    time.Duration
+		wantErr    error
+		wantSent   []int64
+		wantFailed []int64
+	}{
+		{
+			name:     "waits for the in-flight batch",
+			release:  true,
+			timeout:  time.Second,
+			wantSent: []int64{1, 2, 3},
+		},
+		{
+			name:       "records a failed batch",
+			release:    true,
+			publishErr: errors.New("connection reset"),
+			timeout:    time.Second,
+			wantFailed: []int64{1, 2, 3},
+		},
+		{
+			name:    "aborts after the deadline",
+			timeout: 20 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goroutines := runtime.NumGoroutine()
+
+			repo := &stopRepo{benchRepo: benchRepo{batch: benchBatch(3, 1, 1)}}
+			publisher := blockingPublisher{started: make(chan struct{}, 1), release: make(chan struct{}), err: tt.publishErr}
+			s := NewService(Dependencies{Repo: repo, Publisher: publisher, Tx: benchTx{}, Locker: fakeLocker{ok: true}}, config.OutboxConfig{
+				BatchSize:       10,
+				PollMinInterval: time.Hour,
+			})
+
+			p := s.StartProcessor(context.Background())
+			s.signal()
+			select {
+			case <-publisher.started:
+			case <-time.After(time.Second):
+				t.Fatal("batch not published")
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			stopped := make(chan error, 1)
+			go func() { stopped <- p.Stop(ctx) }()
+
+			if tt.release {
+				select {
+				case err := <-stopped:
+					t.Fatalf("Stop() = %v before the batch finished", err)
+				case <-time.After(20 * time.Millisecond):
+				}
+				close(publisher.release)
+			}
+
+			select {
+			case err := <-stopped:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Stop() = %v, want %v", err, tt.wantErr)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Stop did not return")
+			}
+
+			if !reflect.DeepEqual(repo.sent, tt.wantSent) {
+				t.Errorf("marked sent %v, want %v", repo.sent, tt.wantSent)
+			}
+			if !reflect.DeepEqual(repo.failed, tt.wantFailed) {
+				t.Errorf("recorded failures %v, want %v", repo.failed, tt.wantFailed)
+			}
+			for _, err := range repo.ctxErrs {
+				if err != nil {
+					t.Errorf("status recorded on a done context: %v", err)
+				}
+			}
+
+			// the processor and its publish workers are gone
+			deadline := time.Now().Add(time.Second)
+			for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+				time.Sleep(5 * time.Millisecond)
+			}
+			if n := runtime.NumGoroutine(); n > goroutines {
+				t.Errorf("%d goroutines left running, %d before", n, goroutines)
+			}
+		})
+	}
+}
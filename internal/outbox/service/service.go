@@ -3,70 +3,174 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"ice/config"
+	"ice/internal/event"
 	"ice/internal/outbox"
 	"ice/internal/port"
 	"ice/pkg/logger"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type Service struct {
 	repo      port.OutboxRepository
 	publisher port.RedisStreamPublisher
+	tx        port.TxManager
+	locker    port.Locker
+	notifier  port.OutboxNotifier
+	validator port.SchemaValidator
+	encoder   port.EventEncoder
+	cfg       config.OutboxConfig
+
+	// wake carries at most one pending in-process wake-up for the processor
+	wake chan struct{}
+}
+
+type Dependencies struct {
+	Repo      port.OutboxRepository
+	Publisher port.RedisStreamPublisher
+	Tx        port.TxManager
+	// Locker elects the instance that publishes, so a message is never
+	// fetched by two processors at once
+	Locker port.Locker
+	// Notifier is optional and only needed to wake processors running on
+	// other instances
+	Notifier port.OutboxNotifier
+	// Validator is optional, without it events are not checked against
+	// their schema
+	Validator port.SchemaValidator
+	// Encoder is optional, without it events are stored as JSON
+	Encoder port.EventEncoder
 }
 
-func NewService(repo port.OutboxRepository, pub port.RedisStreamPublisher) *Service {
-	return &Service{repo: repo, publisher: pub}
+func NewService(deps Dependencies, cfg config.OutboxConfig) *Service {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 30
+	}
+	if cfg.PollMinInterval <= 0 {
+		cfg.PollMinInterval = 100 * time.Millisecond
+	}
+	if cfg.PollMaxInterval < cfg.PollMinInterval {
+		cfg.PollMaxInterval = cfg.PollMinInterval
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 5 * time.Minute
+	}
+
+	return &Service{
+		repo:      deps.Repo,
+		publisher: deps.Publisher,
+		tx:        deps.Tx,
+		locker:    deps.Locker,
+		notifier:  deps.Notifier,
+		validator: deps.Validator,
+		encoder:   deps.Encoder,
+		cfg:       cfg,
+		wake:      make(chan struct{}, 1),
+	}
 }
 
-func (s *Service) Write(ctx context.Context, topic string, event any) error {
-	body, err := json.Marshal(event)
+func (s *Service) Write(ctx context.Context, topic, aggregateID string, e event.Event) error {
+	msg, err := s.message(topic, aggregateID, e)
 	if err != nil {
 		return err
 	}
+	if err := s.repo.Insert(ctx, msg); err != nil {
+		return err
+	}
 
-	return s.repo.Insert(ctx, &outbox.OutboxItem{
-		Topic:   topic,
-		Payload: string(body),
-	})
+	// the row is only visible to the processor once the surrounding
+	// transaction commits
+	s.tx.AfterCommit(ctx, s.signal)
+	return nil
 }
 
-func (s *Service) StartProcessor(ctx context.Context) {
-	go func() {
-		ticker := time.NewTicker(2 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				logger.Get().Info("Outbox processor stopped")
-				return
-
-			case <-ticker.C:
-				s.process(ctx)
-			}
+// WriteBatch queues events for topic in order with a single insert. Each
+// event is validated and encoded as by Write; if one fails nothing is
+// written.
+func (s *Service) WriteBatch(ctx context.Context, topic string, events []port.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	msgs := make([]*outbox.OutboxItem, len(events))
+	for i, e := range events {
+		msg, err := s.message(topic, e.AggregateID, e.Event)
+		if err != nil {
+			return err
 		}
-	}()
+		msgs[i] = msg
+	}
+	if err := s.repo.InsertBatch(ctx, msgs); err != nil {
+		return err
+	}
+
+	s.tx.AfterCommit(ctx, s.signal)
+	return nil
 }
 
-func (s *Service) process(ctx context.Context) {
-	msgs, err := s.repo.FetchPending(ctx, 30)
+// message validates e and encodes it into an outbox row
+func (s *Service) message(topic, aggregateID string, e event.Event) (*outbox.OutboxItem, error) {
+	body, err := json.Marshal(e)
 	if err != nil {
-		logger.Get().Error("failed to fetch pending outbox", zap.Error(err))
-		return
+		return nil, err
 	}
 
-	for _, msg := range msgs {
-		var data any
-		json.Unmarshal([]byte(msg.Payload), &data)
+	if s.validator != nil {
+		if err := s.validator.Validate(e.EventType(), e.EventVersion(), body); err != nil {
+			return nil, fmt.Errorf("refusing to write invalid event: %w", err)
+		}
+	}
 
-		if err := s.publisher.Publish(ctx, msg.Topic, data); err != nil {
-			logger.Get().Error("failed to publish", zap.Error(err))
-			s.repo.MarkFailed(ctx, msg.ID)
-			continue
+	payload, contentType := body, event.ContentTypeJSON
+	if s.encoder != nil {
+		payload, contentType, err = s.encoder.Encode(topic, e, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event for %s: %w", topic, err)
 		}
+	}
+
+	return &outbox.OutboxItem{
+		MessageID:     uuid.New().String(),
+		Topic:         topic,
+		AggregateID:   aggregateID,
+		EventType:     e.EventType(),
+		SchemaVersion: e.EventVersion(),
+		Payload:       payload,
+		ContentType:   contentType,
+	}, nil
+}
 
-		s.repo.MarkSent(ctx, msg.ID)
+// signal wakes the local processor and nudges the other instances
+func (s *Service) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
 	}
+
+	if s.notifier == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), bookkeepingTimeout)
+		defer cancel()
+		if err := s.notifier.Notify(ctx); err != nil {
+			logger.Get().Warn("failed to notify outbox processors", zap.Error(err))
+		}
+	}()
+}
+
+// Stats reports the backlog: pending and failed counts, the age of the
+// oldest pending message and when a message was last published
+func (s *Service) Stats(ctx context.Context) (outbox.Stats, error) {
+	return s.repo.Stats(ctx)
 }
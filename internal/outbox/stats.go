@@ -0,0 +1,38 @@
+package outbox
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stats describes the outbox backlog
+type Stats struct {
+	Pending          int64
+	Failed           int64
+	OldestPendingAge time.Duration
+	// LastPublishedAt is nil until a message has been sent
+	LastPublishedAt *time.Time
+}
+
+// Thresholds beyond which the outbox is reported as lagging; zero values
+// disable the corresponding check
+type Thresholds struct {
+	MaxLag     time.Duration
+	MaxPending int64
+	MaxFailed  int64
+}
+
+// Problems lists every threshold stats exceed
+func (t Thresholds) Problems(stats Stats) []string {
+	var problems []string
+	if t.MaxLag > 0 && stats.OldestPendingAge > t.MaxLag {
+		problems = append(problems, fmt.Sprintf("oldest pending message is %s old, threshold %s", stats.OldestPendingAge, t.MaxLag))
+	}
+	if t.MaxPending > 0 && stats.Pending > t.MaxPending {
+		problems = append(problems, fmt.Sprintf("%d pending messages, threshold %d", stats.Pending, t.MaxPending))
+	}
+	if t.MaxFailed > 0 && stats.Failed > t.MaxFailed {
+		problems = append(problems, fmt.Sprintf("%d failed messages, threshold %d", stats.Failed, t.MaxFailed))
+	}
+	return problems
+}
@@ -2,32 +2,198 @@ package port
 
 import (
 	"context"
+	"ice/internal/audit"
+	"ice/internal/calendar"
+	"ice/internal/event"
 	"ice/internal/outbox"
 	"ice/internal/todo"
+	"time"
 )
 
 // Repository abstracts persisting and retrieving todo items
 type TodoRepository interface {
 	Create(ctx context.Context, item *todo.TodoItem) error
+	// CreateBatch creates items with as few statements as possible
+	CreateBatch(ctx context.Context, items []*todo.TodoItem) error
+	// Get and GetForUpdate only see live todos, not the trash
+	Get(ctx context.Context, id string) (*todo.TodoItem, error)
+	// GetForUpdate locks the todo until the transaction bound to ctx ends
+	GetForUpdate(ctx context.Context, id string) (*todo.TodoItem, error)
+	GetDeletedForUpdate(ctx context.Context, id string) (*todo.TodoItem, error)
+	ListDeleted(ctx context.Context, limit, offset int) ([]todo.TodoItem, error)
+	// ListAfter pages through live todos in id order
+	ListAfter(ctx context.Context, afterID string, limit int) ([]todo.TodoItem, error)
+	// FindExternalIDs maps the taken external IDs to their todo
+	FindExternalIDs(ctx context.Context, externalIDs []string) (map[string]string, error)
+	// CreateImported creates items like CreateBatch but skips those whose
+	// external ID got taken meanwhile, mapping their ids to the todo
+	// holding it
+	CreateImported(ctx context.Context, items []*todo.TodoItem) (map[string]string, error)
+	// FindPurgeable lists todos deleted before the given time
+	FindPurgeable(ctx context.Context, before time.Time, limit int) ([]todo.TodoItem, error)
+	// Update, Complete, Delete, Restore and Purge only apply while the
+	// todo is still at item.Version and return todo.ErrVersionConflict
+	// otherwise. Delete moves the todo to the trash, Purge removes it from
+	// there for good. Update forgets the reminders sent when the due
+	// date moves.
+	Update(ctx context.Context, item *todo.TodoItem) error
+	Complete(ctx context.Context, item *todo.TodoItem) error
+	Delete(ctx context.Context, item *todo.TodoItem) error
+	Restore(ctx context.Context, item *todo.TodoItem) error
+	Purge(ctx context.Context, item *todo.TodoItem) error
 }
 
 // TodoService abstracts the service for todo business logic
 type TodoService interface {
 	CreateTodo(ctx context.Context, item *todo.TodoItem) error
+	GetTodo(ctx context.Context, id string) (*todo.TodoItem, error)
+	// UpdateTodo applies the editable fields of item to the stored todo
+	// and fills item with the result
+	UpdateTodo(ctx context.Context, item *todo.TodoItem) error
+	// CompleteTodo completes a todo and, when it recurs, creates the next
+	// occurrence, returned as next
+	CompleteTodo(ctx context.Context, id string, version int) (completed, next *todo.TodoItem, err error)
+	// DeleteTodo moves a todo to the trash, RestoreTodo brings it back
+	DeleteTodo(ctx context.Context, id string, version int) error
+	RestoreTodo(ctx context.Context, id string, version int) (*todo.TodoItem, error)
+	// The version passed to the writes above (item.Version for UpdateTodo)
+	// must match the stored one, or be zero to skip the check; a mismatch
+	// returns todo.ErrVersionConflict
+
+	// ListTrash returns a page of deleted todos, most recent first
+	ListTrash(ctx context.Context, limit, offset int) ([]todo.TodoItem, error)
+	// Batch runs several writes in one transaction, see todo.Operation;
+	// the result of each is nil on success
+	Batch(ctx context.Context, ops []todo.Operation, atomic bool) ([]error, error)
+	// ExportTodos calls fn for every live todo in id order, fetching them
+	// a page at a time
+	ExportTodos(ctx context.Context, fn func(item *todo.TodoItem) error) error
+	// ImportTodos creates items, skipping those whose external ID is
+	// taken; the id of the todo holding it is returned at their index.
+	// With dryRun nothing is written.
+	ImportTodos(ctx context.Context, items []*todo.TodoItem, dryRun bool) (duplicates []string, err error)
+	// History returns a page of the audit log of a todo, oldest first
+	History(ctx context.Context, id string, limit, offset int) ([]audit.Entry, error)
+}
+
+// AuditRepository stores the append-only audit log of todo mutations
+type AuditRepository interface {
+	// Append writes entry in the transaction bound to ctx, filling its ID
+	// and creation time
+	Append(ctx context.Context, entry *audit.Entry) error
+	// AppendBatch writes entries like Append but leaves their ID unset
+	AppendBatch(ctx context.Context, entries []*audit.Entry) error
+	List(ctx context.Context, todoID string, limit, offset int) ([]audit.Entry, error)
+}
+
+// CalendarTokenRepository stores the tokens of calendar feeds
+type CalendarTokenRepository interface {
+	// Save stores token, replacing the one its owner had
+	Save(ctx context.Context, token *calendar.Token) error
+	Delete(ctx context.Context, owner string) error
+	FindByHash(ctx context.Context, hash []byte) (*calendar.Token, error)
+}
+
+// CalendarService hands out the secret tokens of calendar feed URLs
+type CalendarService interface {
+	// IssueToken returns a new token of owner with its secret, revoking
+	// the previous one
+	IssueToken(ctx context.Context, owner string) (*calendar.Token, string, error)
+	RevokeToken(ctx context.Context, owner string) error
+	// Authenticate returns the token of a secret, calendar.ErrTokenNotFound
+	// when it is unknown or was revoked
+	Authenticate(ctx context.Context, secret string) (*calendar.Token, error)
+}
+
+// TxManager runs fn in a database transaction; repositories called with
+// the ctx handed to fn take part in it
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit defers fn until the transaction bound to ctx commits,
+	// or runs it right away when there is none
+	AfterCommit(ctx context.Context, fn func())
+}
+
+// Locker hands out a lock shared by all instances of the service
+type Locker interface {
+	// Lock waits up to wait for the named lock; ok is false when another
+	// instance holds it. release must be called once ok is true.
+	Lock(ctx context.Context, name string, wait time.Duration) (release func(), ok bool, err error)
+}
+
+// StreamMessage is a single entry appended to a stream
+type StreamMessage struct {
+	// ID is the producer assigned message ID, identical across retries
+	ID     string
+	Stream string
+	// Payload is published byte for byte as the producer encoded it
+	Payload []byte
+	// Headers carry metadata such as the payload content type
+	Headers map[string]string
 }
 
 // RedisStreamPublisher abstracts publishing todo items to a Redis Stream
 type RedisStreamPublisher interface {
-	Publish(ctx context.Context, stream string, data interface{}) error
+	Publish(ctx context.Context, msg StreamMessage) error
+	// PublishBatch appends the messages of a single stream in order, at
+	// least once: on error any prefix of the batch may already have been
+	// added, and retrying appends those entries again under the same ID.
+	// Consumers deduplicate by message_id; messages already published
+	// under the same ID may be skipped.
+	PublishBatch(ctx context.Context, msgs []StreamMessage) error
+}
+
+// OutboxNotifier nudges outbox processors on other instances when new
+// messages are committed
+type OutboxNotifier interface {
+	Notify(ctx context.Context) error
+	// Subscribe delivers a value per nudge until ctx is cancelled
+	Subscribe(ctx context.Context) <-chan struct{}
 }
 
 type OutboxWriter interface {
-	Write(ctx context.Context, topic string, event any) error
+	// Write queues e for topic; events sharing an aggregateID are
+	// published in the order they were written
+	Write(ctx context.Context, topic, aggregateID string, e event.Event) error
+	// WriteBatch queues events for topic in one go, in order
+	WriteBatch(ctx context.Context, topic string, events []OutboxEvent) error
+}
+
+// OutboxEvent is an event queued for the aggregate it belongs to
+type OutboxEvent struct {
+	AggregateID string
+	Event       event.Event
+}
+
+// EventEncoder turns an event into the wire format configured for topic,
+// starting from its validated JSON form
+type EventEncoder interface {
+	Encode(topic string, e event.Event, jsonPayload []byte) (payload []byte, contentType string, err error)
+}
+
+// SchemaValidator checks an encoded event against its versioned contract
+type SchemaValidator interface {
+	Validate(eventType string, version int, payload []byte) error
 }
 
 type OutboxRepository interface {
 	Insert(ctx context.Context, msg *outbox.OutboxItem) error
+	// InsertBatch inserts msgs in order, ids growing with it
+	InsertBatch(ctx context.Context, msgs []*outbox.OutboxItem) error
 	FetchPending(ctx context.Context, limit int) ([]outbox.OutboxItem, error)
-	MarkSent(ctx context.Context, id int64) error
-	MarkFailed(ctx context.Context, id int64) error
+	MarkSent(ctx context.Context, ids []int64) error
+	RecordFailures(ctx context.Context, ids []int64, maxAttempts int, maxBackoff time.Duration) error
+	Stats(ctx context.Context) (outbox.Stats, error)
+}
+
+// OutboxMonitor exposes the outbox backlog for health and admin reporting
+type OutboxMonitor interface {
+	Stats(ctx context.Context) (outbox.Stats, error)
+}
+
+type ReminderRepository interface {
+	FindDue(ctx context.Context, window string, from, to time.Time, limit int) ([]todo.TodoItem, error)
+	// Record claims the reminder of a todo for window, false when it was
+	// claimed before
+	Record(ctx context.Context, todoID, window string) (bool, error)
 }
@@ -0,0 +1,66 @@
+package reminder
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// WindowOverdue is the window of todos whose due date has passed
+const WindowOverdue = "overdue"
+
+// Window selects the todos due in (now+From, now+To]. A reminder is
+// emitted at most once per todo and window.
+type Window struct {
+	Name string
+	From time.Duration
+	To   time.Duration
+}
+
+// Overdue reports whether the window covers due dates in the past
+func (w Window) Overdue() bool {
+	return w.Name == WindowOverdue
+}
+
+// Windows builds the due soon windows from their lead times plus the
+// overdue window reaching lookback into the past. Due soon windows do not
+// overlap: a todo due in 30m with lead times 24h and 1h only falls in the
+// 1h window, so it is not reminded twice at once.
+func Windows(leadTimes []time.Duration, lookback time.Duration) []Window {
+	leads := make([]time.Duration, 0, len(leadTimes))
+	for _, d := range leadTimes {
+		if d > 0 {
+			leads = append(leads, d)
+		}
+	}
+	sort.Slice(leads, func(i, j int) bool { return leads[i] > leads[j] })
+
+	windows := make([]Window, 0, len(leads)+1)
+	for i, d := range leads {
+		var from time.Duration
+		if i+1 < len(leads) {
+			from = leads[i+1]
+		}
+		if from == d {
+			continue
+		}
+		windows = append(windows, Window{Name: "due_in_" + formatDuration(d), From: from, To: d})
+	}
+	if lookback > 0 {
+		windows = append(windows, Window{Name: WindowOverdue, From: -lookback, To: 0})
+	}
+	return windows
+}
+
+// formatDuration drops the zero units time.Duration.String appends,
+// e.g. 24h instead of 24h0m0s
+func formatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
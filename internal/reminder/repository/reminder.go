@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"ice/internal/todo"
+	"time"
+)
+
+// FindDue returns up to limit open, live todos due in (from, to] that have not
+// been reminded for window yet, earliest due first. It may read from a replica:
+// a todo already reminded can show up again, Record is what guarantees a
+// single reminder.
+func (r *Repository) FindDue(ctx context.Context, window string, from, to time.Time, limit int) ([]todo.TodoItem, error) {
+	rows, err := r.mysql.Reader(ctx).QueryContext(ctx, `
+		SELECT t.id, t.description, t.due_date
+		FROM todos t
+		WHERE t.due_date > ? AND t.due_date <= ? AND NOT t.completed AND t.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM todo_reminders r
+			WHERE r.todo_id = t.id AND r.reminder_window = ?
+		  )
+		ORDER BY t.due_date
+		LIMIT ?`,
+		from, to, window, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []todo.TodoItem
+	for rows.Next() {
+		var item todo.TodoItem
+		if err := rows.Scan(&item.ID, &item.Description, &item.DueDate); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Record claims the reminder of todoID for window and reports whether this
+// call claimed it, false meaning it was already sent
+func (r *Repository) Record(ctx context.Context, todoID, window string) (bool, error) {
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		"INSERT IGNORE INTO todo_reminders (todo_id, reminder_window) VALUES (?, ?)",
+		todoID, window,
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"ice/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Scheduler is the handle of a running reminder scheduler
+type Scheduler struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartScheduler scans for due reminders every Interval until ctx is
+// cancelled or Stop is called
+func (s *Service) StartScheduler(ctx context.Context) *Scheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	sc := &Scheduler{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(sc.done)
+
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Get().Info("Reminder scheduler stopped")
+				return
+			case <-ticker.C:
+			}
+
+			sent, err := s.Scan(ctx)
+			if err != nil && ctx.Err() == nil {
+				logger.Get().Error("reminder scan failed", zap.Error(err))
+			}
+			if sent > 0 {
+				logger.Get().Info("reminders emitted", zap.Int("count", sent))
+			}
+		}
+	}()
+
+	return sc
+}
+
+// Stop cancels the scheduler and waits for the running scan to return
+func (sc *Scheduler) Stop(ctx context.Context) error {
+	sc.cancel()
+
+	select {
+	case <-sc.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
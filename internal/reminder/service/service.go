@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"ice/config"
+	"ice/internal/event"
+	"ice/internal/port"
+	"ice/internal/reminder"
+	"ice/internal/todo"
+	"time"
+)
+
+// lockName is the MySQL lock electing the instance that runs a scan
+const lockName = "ice.reminder.scheduler"
+
+type Service struct {
+	repo    port.ReminderRepository
+	outbox  port.OutboxWriter
+	tx      port.TxManager
+	locker  port.Locker
+	windows []reminder.Window
+	cfg     config.ReminderConfig
+}
+
+type Dependencies struct {
+	Repo   port.ReminderRepository
+	Outbox port.OutboxWriter
+	Tx     port.TxManager
+	Locker port.Locker
+}
+
+func NewService(deps Dependencies, cfg config.ReminderConfig) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+
+	return &Service{
+		repo:    deps.Repo,
+		outbox:  deps.Outbox,
+		tx:      deps.Tx,
+		locker:  deps.Locker,
+		windows: reminder.Windows(cfg.Windows, cfg.OverdueLookback),
+		cfg:     cfg,
+	}
+}
+
+// Scan emits the reminders that are due and returns how many were sent.
+// Only one instance scans at a time; the others skip the round. Each
+// window handles up to BatchSize todos per scan, the rest are picked up by
+// the next one.
+func (s *Service) Scan(ctx context.Context) (int, error) {
+	release, ok, err := s.locker.Lock(ctx, lockName, 0)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer release()
+
+	now := time.Now().UTC()
+	sent := 0
+	for _, w := range s.windows {
+		items, err := s.repo.FindDue(ctx, w.Name, now.Add(w.From), now.Add(w.To), s.cfg.BatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range items {
+			ok, err := s.remind(ctx, &items[i], w)
+			if err != nil {
+				return sent, err
+			}
+			if ok {
+				sent++
+			}
+		}
+	}
+	return sent, nil
+}
+
+// remind claims the reminder and writes its event in one transaction, so
+// the event is queued exactly once per todo and window
+func (s *Service) remind(ctx context.Context, item *todo.TodoItem, w reminder.Window) (bool, error) {
+	claimed := false
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		claimed, err = s.repo.Record(ctx, item.ID, w.Name)
+		if err != nil || !claimed {
+			return err
+		}
+
+		var e event.Event = event.NewTodoDueSoon(item, w.Name)
+		if w.Overdue() {
+			e = event.NewTodoOverdue(item)
+		}
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, e)
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to emit %s reminder for todo %s: %w", w.Name, item.ID, err)
+	}
+	return claimed, nil
+}
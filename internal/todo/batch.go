@@ -0,0 +1,23 @@
+package todo
+
+import "errors"
+
+// ErrBatchAborted is the result of the operations of an all-or-nothing
+// batch that were rolled back because another one failed
+var ErrBatchAborted = errors.New("rolled back, another operation of the batch failed")
+
+type OpKind string
+
+const (
+	OpCreate OpKind = "create"
+	OpUpdate OpKind = "update"
+	OpDelete OpKind = "delete"
+)
+
+// Operation is a single write of a batch. Item is the todo to create, or
+// the id, expected version and new fields of the todo to update; a delete
+// only reads the id and version. Item is filled with the result.
+type Operation struct {
+	Kind OpKind
+	Item *TodoItem
+}
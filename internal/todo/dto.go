@@ -5,8 +5,77 @@ import "time"
 type CreateTodoRequest struct {
 	Description string    `json:"description" validate:"required,min=1" example:"test task"`
 	DueDate     time.Time `json:"dueDate" validate:"required" example:"2025-01-01T06:00:00Z"`
+	// TimeZone is the IANA zone the todo is planned in, used for all-day
+	// dates, recurrence and as the default zone of responses
+	TimeZone string `json:"timeZone,omitempty" validate:"omitempty,timezone" example:"Europe/Berlin"`
+	// AllDay makes the calendar date of DueDate, as written, the due date
+	AllDay bool `json:"allDay,omitempty" example:"false"`
+	// Recurrence is an iCalendar RRULE subset: FREQ=DAILY|WEEKLY|MONTHLY
+	// with optional INTERVAL, BYDAY and UNTIL or COUNT
+	Recurrence string `json:"recurrence,omitempty" validate:"omitempty,rrule" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
 }
 
 type CreateTodoResponse struct {
 	TodoItem TodoItem `json:"todoItem"`
 }
+
+type UpdateTodoRequest struct {
+	Description string    `json:"description" validate:"required,min=1" example:"test task"`
+	DueDate     time.Time `json:"dueDate" validate:"required" example:"2025-01-01T06:00:00Z"`
+	// TimeZone is the IANA zone the todo is planned in, used for all-day
+	// dates, recurrence and as the default zone of responses
+	TimeZone string `json:"timeZone,omitempty" validate:"omitempty,timezone" example:"Europe/Berlin"`
+	// AllDay makes the calendar date of DueDate, as written, the due date
+	AllDay bool `json:"allDay,omitempty" example:"false"`
+	// Recurrence replaces the rule, empty makes the todo a one-off
+	Recurrence string `json:"recurrence,omitempty" validate:"omitempty,rrule" example:"FREQ=WEEKLY;BYDAY=MO,WE"`
+}
+
+type TodoResponse struct {
+	TodoItem TodoItem `json:"todoItem"`
+}
+
+type CompleteTodoResponse struct {
+	TodoItem TodoItem `json:"todoItem"`
+	// Next is the todo created for the next occurrence of a recurring todo
+	Next *TodoItem `json:"next,omitempty"`
+}
+
+type TrashResponse struct {
+	TodoItems []TodoItem `json:"todoItems"`
+	Limit     int        `json:"limit" example:"50"`
+	Offset    int        `json:"offset" example:"0"`
+}
+
+type BatchRequest struct {
+	// Atomic rolls the whole batch back when an operation fails, instead
+	// of skipping the failed operations
+	Atomic     bool             `json:"atomic,omitempty" example:"false"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation creates a todo from Todo, replaces the fields of todo ID
+// with Todo, or deletes todo ID. Version plays the part of If-Match for
+// updates and deletes.
+type BatchOperation struct {
+	Op      string             `json:"op" validate:"required,oneof=create update delete" example:"update"`
+	ID      string             `json:"id,omitempty" validate:"required_unless=Op create,excluded_if=Op create" example:"4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"`
+	Version int                `json:"version,omitempty" validate:"required_unless=Op create,excluded_if=Op create,omitempty,gte=1" example:"3"`
+	Todo    *CreateTodoRequest `json:"todo,omitempty" validate:"required_unless=Op delete,excluded_if=Op delete"`
+}
+
+type BatchResponse struct {
+	Atomic    bool          `json:"atomic" example:"false"`
+	Succeeded int           `json:"succeeded" example:"2"`
+	Failed    int           `json:"failed" example:"1"`
+	Results   []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of the operation at Index, with the HTTP
+// status its own endpoint would have answered
+type BatchResult struct {
+	Index    int       `json:"index" example:"0"`
+	Status   int       `json:"status" example:"200"`
+	TodoItem *TodoItem `json:"todoItem,omitempty"`
+	Error    string    `json:"error,omitempty" example:"todo was modified concurrently"`
+}
@@ -1,13 +1,78 @@
 package todo
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	ErrNotFound         = errors.New("todo not found")
+	ErrAlreadyCompleted = errors.New("todo already completed")
+	// ErrVersionConflict means the todo changed since it was read
+	ErrVersionConflict = errors.New("todo was modified concurrently")
+	ErrNotInTrash      = errors.New("todo not in trash")
+)
+
 // TodoItem is the core domain entity for a todo item
 // Contains UUID, description, and due date
 type TodoItem struct {
-	ID          string    // UUID
-	Description string    // Description
-	DueDate     time.Time // Due date
+	ID          string     // UUID
+	ExternalID  string     // ID in the tool the todo was imported from, empty if none
+	Description string     // Description
+	DueDate     time.Time  // Due date, UTC once stored
+	TimeZone    string     // IANA zone the todo is planned in, empty for UTC
+	AllDay      bool       // Due on a date rather than at a time
+	Recurrence  string     // RRULE, empty for a one-off todo
+	Occurrence  int        // Position in its recurring series, starting at 1
+	Completed   bool       // Completion state
+	CompletedAt *time.Time // Completion time, nil while open
+	Version     int        // Incremented on every write, for optimistic locking
+	CreatedAt   time.Time  // Creation time
+	UpdatedAt   time.Time  // Last write time
+	DeletedAt   *time.Time // Time the todo was moved to the trash, nil while live
+}
+
+// Location is the zone the todo is planned in, UTC when unset
+func (t *TodoItem) Location() *time.Location {
+	if t.TimeZone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NormalizeDueDate converts the due date to UTC for storage. An all-day
+// due date keeps the calendar date it was given with and is pinned to the
+// start of that day in the todo's zone.
+func (t *TodoItem) NormalizeDueDate() {
+	if t.AllDay {
+		y, m, d := t.DueDate.Date()
+		t.DueDate = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+	t.DueDate = t.DueDate.UTC()
+}
+
+// In returns a copy of the todo with its times rendered in loc. An all-day
+// due date keeps its calendar date and is rendered as midnight in loc.
+func (t TodoItem) In(loc *time.Location) TodoItem {
+	if t.AllDay {
+		y, m, d := t.DueDate.In(t.Location()).Date()
+		t.DueDate = time.Date(y, m, d, 0, 0, 0, 0, loc)
+	} else {
+		t.DueDate = t.DueDate.In(loc)
+	}
+	if t.CompletedAt != nil {
+		completedAt := t.CompletedAt.In(loc)
+		t.CompletedAt = &completedAt
+	}
+	if t.DeletedAt != nil {
+		deletedAt := t.DeletedAt.In(loc)
+		t.DeletedAt = &deletedAt
+	}
+	t.CreatedAt = t.CreatedAt.In(loc)
+	t.UpdatedAt = t.UpdatedAt.In(loc)
+	return t
 }
@@ -0,0 +1,30 @@
+package exchange
+
+type ImportResponse struct {
+	// DryRun reports what would have been imported, nothing was written
+	DryRun  bool `json:"dryRun" example:"false"`
+	Total   int  `json:"total" example:"3"`
+	Created int  `json:"created" example:"1"`
+	// Skipped counts the records whose external ID is already taken
+	Skipped    int               `json:"skipped" example:"1"`
+	Failed     int               `json:"failed" example:"1"`
+	Errors     []ImportRowError  `json:"errors"`
+	Duplicates []ImportDuplicate `json:"duplicates"`
+	// Error is set when the import stopped before its end
+	Error string `json:"error,omitempty" example:"record 3: unexpected EOF"`
+}
+
+// ImportRowError is a record that was not imported
+type ImportRowError struct {
+	Row        int    `json:"row" example:"2"`
+	ExternalID string `json:"externalId,omitempty" example:"legacy-42"`
+	Error      string `json:"error" example:"description is required"`
+}
+
+// ImportDuplicate is a record left out as its external ID is taken
+type ImportDuplicate struct {
+	Row        int    `json:"row" example:"3"`
+	ExternalID string `json:"externalId" example:"legacy-7"`
+	// ID of the todo holding the external ID
+	ID string `json:"id" example:"4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10"`
+}
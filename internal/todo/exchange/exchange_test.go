@@ -0,0 +1,263 @@
+package exchange
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tz database not available:", err)
+	}
+	created := time.Date(2025, 1, 1, 6, 0, 0, 0, time.UTC)
+	completedAt := time.Date(2025, 2, 28, 17, 30, 0, 0, time.UTC)
+
+	records := []Record{
+		{
+			ID: "4f9b2c1e-6a51-4f57-9f43-0c1b7d1f3a10", Description: "buy milk",
+			DueDate: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), Version: 1, CreatedAt: &created, UpdatedAt: &created,
+		},
+		{
+			ID: "9a0c7e52-2f0e-4c3b-8d51-5b6f0e4a7c21", ExternalID: "legacy-7",
+			Description: "milk, eggs; \"bread\"\nand cheese \\ butter",
+			DueDate:     time.Date(2025, 3, 1, 9, 0, 0, 0, berlin), TimeZone: "Europe/Berlin",
+			Recurrence: "FREQ=WEEKLY;BYDAY=MO,WE", Version: 3, CreatedAt: &created, UpdatedAt: &created,
+		},
+		{
+			ID: "b3d1f0a4-7c2e-4e9b-a6d8-1f5c3e7b9a02", Description: "=HYPERLINK(\"http://example.com\")",
+			DueDate: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), AllDay: true,
+			Completed: true, CompletedAt: &completedAt, Version: 2, CreatedAt: &created, UpdatedAt: &created,
+		},
+		{
+			ID: "c8e2a5b7-3d4f-4a1c-9e6b-2d7f8a0c1e53", Description: strings.TrimSpace(strings.Repeat("long ünïcödé text ", 10)),
+			DueDate: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), AllDay: true,
+			Recurrence: "FREQ=DAILY;COUNT=3", Version: 1, CreatedAt: &created, UpdatedAt: &created,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		writer func(io.Writer) Writer
+		// events carry no completion state
+		noCompletion bool
+		// the UID is the id, whatever the external ID
+		uid bool
+	}{
+		{name: "csv", format: CSV},
+		{name: "json", format: JSON},
+		{name: "ndjson", format: NDJSON},
+		{name: "ics events", format: ICS, noCompletion: true, uid: true},
+		{name: "ics todos", format: ICS, writer: func(w io.Writer) Writer { return NewICSWriter(w, Todo) }, uid: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(tt.format, &buf)
+			if tt.writer != nil {
+				w = tt.writer(&buf)
+			}
+			for _, r := range records {
+				if err := w.Write(r); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			got := readAll(t, tt.format, &buf)
+			if len(got) != len(records) {
+				t.Fatalf("read %d records, want %d", len(got), len(records))
+			}
+			for i, r := range records {
+				want := imported(r)
+				if tt.uid {
+					want.ExternalID = r.ID
+				}
+				if tt.noCompletion {
+					want.Completed, want.CompletedAt = false, nil
+				}
+				if diff := compare(got[i], want); diff != "" {
+					t.Errorf("record %d: %s", i+1, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	for _, format := range []Format{CSV, JSON, NDJSON, ICS} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewWriter(format, &buf).Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := readAll(t, format, &buf); len(got) != 0 {
+				t.Errorf("read %d records from an empty export", len(got))
+			}
+		})
+	}
+}
+
+func TestFormulaEscape(t *testing.T) {
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{cell: "", want: ""},
+		{cell: "buy milk", want: "buy milk"},
+		{cell: "=1+2", want: "'=1+2"},
+		{cell: "+49 30 123", want: "'+49 30 123"},
+		{cell: "-5 degrees", want: "'-5 degrees"},
+		{cell: "@SUM(A1)", want: "'@SUM(A1)"},
+		{cell: "\tcmd", want: "'\tcmd"},
+		{cell: "\rcmd", want: "'\rcmd"},
+		{cell: "a=b", want: "a=b"},
+		{cell: "'quoted", want: "'quoted"},
+		{cell: "'=1", want: "''=1"},
+		{cell: "''@x", want: "'''@x"},
+		{cell: "'", want: "'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			got := escapeFormula(tt.cell)
+			if got != tt.want {
+				t.Errorf("escapeFormula(%q) = %q, want %q", tt.cell, got, tt.want)
+			}
+			if back := unescapeFormula(got); back != tt.cell {
+				t.Errorf("unescapeFormula(%q) = %q, want %q", got, back, tt.cell)
+			}
+		})
+	}
+}
+
+func TestImportExternalID(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   string
+	}{
+		{name: "csv external id", format: CSV, input: "id,external_id,description,due_date\na,ext,x,2025-03-01\n", want: "ext"},
+		{name: "csv falls back to id", format: CSV, input: "id,external_id,description,due_date\na,,x,2025-03-01\n", want: "a"},
+		{name: "csv without either", format: CSV, input: "description,due_date\nx,2025-03-01\n", want: ""},
+		{name: "json external id", format: JSON, input: `[{"id":"a","externalId":"ext","description":"x","dueDate":"2025-03-01"}]`, want: "ext"},
+		{name: "json falls back to id", format: JSON, input: `[{"id":" a ","description":"x","dueDate":"2025-03-01"}]`, want: "a"},
+		{name: "ndjson falls back to id", format: NDJSON, input: `{"id":"a","externalId":" ","description":"x","dueDate":"2025-03-01"}`, want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readAll(t, tt.format, strings.NewReader(tt.input))
+			if len(got) != 1 {
+				t.Fatalf("read %d records, want 1", len(got))
+			}
+			if got[0].ExternalID != tt.want {
+				t.Errorf("ExternalID = %q, want %q", got[0].ExternalID, tt.want)
+			}
+		})
+	}
+}
+
+// readAll reads every record of r, failing on any error
+func readAll(t *testing.T, format Format, r io.Reader) []Record {
+	t.Helper()
+	reader, err := NewReader(format, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records []Record
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row.Err != nil {
+			t.Fatalf("record %d: %v", row.Number, row.Err)
+		}
+		records = append(records, row.Record)
+	}
+}
+
+// imported is what an import reads back from the export of r
+func imported(r Record) Record {
+	externalID := r.ExternalID
+	if externalID == "" {
+		externalID = r.ID
+	}
+	return Record{
+		ExternalID:  externalID,
+		Description: r.Description,
+		DueDate:     r.DueDate,
+		TimeZone:    r.TimeZone,
+		AllDay:      r.AllDay,
+		Recurrence:  r.Recurrence,
+		Completed:   r.Completed,
+		CompletedAt: r.CompletedAt,
+	}
+}
+
+// compare describes how got differs from want, times compared as instants
+func compare(got, want Record) string {
+	var diffs []string
+	field := func(name string, got, want any) {
+		if got != want {
+			diffs = append(diffs, fmt.Sprintf("%s: got %#v, want %#v", name, got, want))
+		}
+	}
+	field("externalId", got.ExternalID, want.ExternalID)
+	field("description", got.Description, want.Description)
+	field("timeZone", got.TimeZone, want.TimeZone)
+	field("allDay", got.AllDay, want.AllDay)
+	field("recurrence", got.Recurrence, want.Recurrence)
+	field("completed", got.Completed, want.Completed)
+	if !got.DueDate.Equal(want.DueDate) {
+		diffs = append(diffs, fmt.Sprintf("dueDate: got %v, want %v", got.DueDate, want.DueDate))
+	}
+	if (got.CompletedAt == nil) != (want.CompletedAt == nil) ||
+		got.CompletedAt != nil && !got.CompletedAt.Equal(*want.CompletedAt) {
+		diffs = append(diffs, fmt.Sprintf("completedAt: got %v, want %v", got.CompletedAt, want.CompletedAt))
+	}
+	return strings.Join(diffs, "; ")
+}
+
+func TestICSRule(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("tz database not available:", err)
+	}
+	tests := []struct {
+		name   string
+		rule   string
+		allDay bool
+		loc    *time.Location
+		want   string
+	}{
+		{name: "no until", rule: "FREQ=DAILY;COUNT=3", want: "FREQ=DAILY;COUNT=3"},
+		{name: "all-day with date", rule: "FREQ=DAILY;UNTIL=20250110", allDay: true, want: "FREQ=DAILY;UNTIL=20250110"},
+		{name: "all-day with time", rule: "FREQ=DAILY;UNTIL=20250110T235959Z", allDay: true, want: "FREQ=DAILY;UNTIL=20250110"},
+		{name: "timed with time", rule: "FREQ=DAILY;UNTIL=20250110T120000Z", want: "FREQ=DAILY;UNTIL=20250110T120000Z"},
+		{name: "timed in UTC with date", rule: "FREQ=DAILY;UNTIL=20250110", want: "FREQ=DAILY;UNTIL=20250110T235959Z"},
+		{name: "timed in a zone with date", rule: "FREQ=DAILY;UNTIL=20250110", loc: tokyo, want: "FREQ=DAILY;UNTIL=20250110T145959Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := icsRule(tt.rule, tt.allDay, tt.loc); got != tt.want {
+				t.Errorf("icsRule(%q) = %q, want %q", tt.rule, got, tt.want)
+			}
+		})
+	}
+}
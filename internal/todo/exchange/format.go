@@ -0,0 +1,58 @@
+// Package exchange reads and writes todos as CSV, JSON, NDJSON and
+// iCalendar for export and import
+package exchange
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+)
+
+type Format string
+
+const (
+	CSV    Format = "csv"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	ICS    Format = "ics"
+)
+
+// ParseFormat reads a format name
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case CSV, JSON, NDJSON, ICS:
+		return f, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, use csv, json, ndjson or ics", s)
+}
+
+// FormatOf picks the format of a request body from its content type
+func FormatOf(contentType string) (Format, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+	switch mediaType {
+	case "text/csv":
+		return CSV, nil
+	case "application/json":
+		return JSON, nil
+	case "application/x-ndjson", "application/jsonl":
+		return NDJSON, nil
+	case "text/calendar":
+		return ICS, nil
+	}
+	return "", fmt.Errorf("unsupported content type %q, use text/csv, application/json, application/x-ndjson or text/calendar", contentType)
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	case ICS:
+		return "text/calendar; charset=utf-8"
+	}
+	return "application/json"
+}
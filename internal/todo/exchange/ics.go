@@ -0,0 +1,225 @@
+package exchange
+
+import (
+	"ice/pkg/ical"
+	"ice/pkg/recurrence"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Component is the iCalendar component todos are written as
+type Component string
+
+const (
+	// Event shows todos in calendars, at their due date
+	Event Component = "VEVENT"
+	// Todo keeps them tasks, with their completion state
+	Todo Component = "VTODO"
+)
+
+// ParseComponent reads a component name such as vtodo
+func ParseComponent(s string) (Component, bool) {
+	switch c := Component(strings.ToUpper(s)); c {
+	case Event, Todo:
+		return c, true
+	}
+	return "", false
+}
+
+// prodID names this service as the producer of calendars
+const prodID = "-//ice//todo service//EN"
+
+// icsWriter writes a VCALENDAR with a component per record. The UID of a
+// todo is its id, so calendars keep track of it across exports.
+type icsWriter struct {
+	enc       *ical.Encoder
+	component Component
+	started   bool
+	// zones holds the VTIMEZONEs written so far
+	zones map[string]bool
+}
+
+func NewICSWriter(w io.Writer, component Component) Writer {
+	return &icsWriter{enc: ical.NewEncoder(w), component: component, zones: make(map[string]bool)}
+}
+
+func (iw *icsWriter) start() {
+	if iw.started {
+		return
+	}
+	iw.started = true
+	iw.enc.Begin("VCALENDAR")
+	iw.enc.Prop(ical.Property{Name: "VERSION", Value: "2.0"})
+	iw.enc.Text("PRODID", prodID)
+	iw.enc.Prop(ical.Property{Name: "CALSCALE", Value: "GREGORIAN"})
+	iw.enc.Prop(ical.Property{Name: "METHOD", Value: "PUBLISH"})
+	iw.enc.Text("X-WR-CALNAME", "Todos")
+}
+
+func (iw *icsWriter) Write(r Record) error {
+	iw.start()
+
+	// a timed todo with a zone is written in its local time, so recurring
+	// ones keep their time of day across daylight saving changes
+	var loc *time.Location
+	if r.TimeZone != "" && !r.AllDay {
+		var err error
+		if loc, err = time.LoadLocation(r.TimeZone); err != nil {
+			return err
+		}
+		if !iw.zones[r.TimeZone] {
+			iw.zones[r.TimeZone] = true
+			iw.enc.Component(ical.VTimezone(loc))
+		}
+	}
+	due := func(name string) ical.Property {
+		if r.AllDay {
+			return ical.Date(name, r.DueDate)
+		}
+		return ical.DateTime(name, r.DueDate, loc)
+	}
+
+	c := &ical.Component{Name: string(iw.component)}
+	add := func(p ical.Property) { c.Props = append(c.Props, p) }
+
+	add(ical.Property{Name: "UID", Value: ical.EscapeText(r.ID)})
+	if r.UpdatedAt != nil {
+		add(ical.DateTime("DTSTAMP", *r.UpdatedAt, nil))
+		add(ical.DateTime("LAST-MODIFIED", *r.UpdatedAt, nil))
+	}
+	if r.CreatedAt != nil {
+		add(ical.DateTime("CREATED", *r.CreatedAt, nil))
+	}
+	if r.Version > 0 {
+		add(ical.Property{Name: "SEQUENCE", Value: strconv.Itoa(r.Version - 1)})
+	}
+	add(ical.Property{Name: "SUMMARY", Value: ical.EscapeText(r.Description)})
+
+	switch {
+	case iw.component == Event:
+		add(due("DTSTART"))
+	case r.Recurrence != "":
+		// a recurrence needs DTSTART, and DUE would have to come after it
+		add(due("DTSTART"))
+	default:
+		add(due("DUE"))
+	}
+	if r.Recurrence != "" {
+		add(ical.Property{Name: "RRULE", Value: icsRule(r.Recurrence, r.AllDay, loc)})
+	}
+
+	if iw.component == Todo {
+		status := "NEEDS-ACTION"
+		if r.Completed {
+			status = "COMPLETED"
+		}
+		add(ical.Property{Name: "STATUS", Value: status})
+		if r.CompletedAt != nil {
+			add(ical.DateTime("COMPLETED", *r.CompletedAt, nil))
+		}
+	}
+
+	iw.enc.Component(c)
+	return nil
+}
+
+func (iw *icsWriter) Flush() error {
+	return iw.enc.Flush()
+}
+
+// Close ends the calendar, an empty one for an empty export
+func (iw *icsWriter) Close() error {
+	iw.start()
+	iw.enc.End("VCALENDAR")
+	return iw.enc.Flush()
+}
+
+// icsRule gives UNTIL the type of DTSTART, as RFC 5545 requires: a date
+// for an all-day todo and a UTC time for a timed one, a date UNTIL then
+// covering its whole day in loc, the zone of the todo
+func icsRule(rule string, allDay bool, loc *time.Location) string {
+	r, err := recurrence.Parse(rule)
+	if err != nil || r.Until.IsZero() || r.UntilDate == allDay {
+		return rule
+	}
+	if allDay {
+		y, m, d := r.Until.UTC().Date()
+		r.Until, r.UntilDate = time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+		return r.String()
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	y, m, d := r.Until.Date()
+	r.Until, r.UntilDate = time.Date(y, m, d+1, 0, 0, 0, 0, loc).Add(-time.Second), false
+	return r.String()
+}
+
+// icsReader reads the VEVENTs and VTODOs of a calendar as records, the UID
+// becoming the external ID
+type icsReader struct {
+	dec *ical.Decoder
+	n   int
+}
+
+func (ir *icsReader) Next() (Row, error) {
+	for {
+		c, err := ir.dec.Next()
+		if err != nil {
+			return Row{}, err
+		}
+		// a RECURRENCE-ID marks a changed instance of a series, which
+		// todos have no place for
+		if (c.Name != string(Event) && c.Name != string(Todo)) || c.Prop("RECURRENCE-ID") != nil {
+			continue
+		}
+
+		ir.n++
+		rec, err := icsRecord(c)
+		return Row{Number: ir.n, Record: rec, Err: err}, nil
+	}
+}
+
+// icsRecord maps a VEVENT or VTODO to a record: a VTODO is due at its DUE,
+// falling back to DTSTART as an event is. A date makes the todo all-day.
+func icsRecord(c *ical.Component) (Record, error) {
+	var rec Record
+	if p := c.Prop("UID"); p != nil {
+		rec.ExternalID = strings.TrimSpace(p.Text())
+	}
+	if p := c.Prop("SUMMARY"); p != nil {
+		rec.Description = strings.TrimSpace(p.Text())
+	}
+
+	due := c.Prop("DTSTART")
+	if p := c.Prop("DUE"); p != nil && c.Name == string(Todo) {
+		due = p
+	}
+	if due != nil {
+		t, date, err := due.Time()
+		if err != nil {
+			return Record{}, err
+		}
+		rec.DueDate, rec.AllDay = t, date
+		if !date && due.Param("TZID") != "" {
+			rec.TimeZone = t.Location().String()
+		}
+	}
+	if p := c.Prop("RRULE"); p != nil {
+		rec.Recurrence = p.Value
+	}
+
+	if p := c.Prop("STATUS"); p != nil && strings.EqualFold(p.Value, "COMPLETED") {
+		rec.Completed = true
+	}
+	if p := c.Prop("COMPLETED"); p != nil {
+		t, _, err := p.Time()
+		if err != nil {
+			return Record{}, err
+		}
+		rec.CompletedAt = &t
+	}
+	return rec, nil
+}
@@ -0,0 +1,256 @@
+package exchange
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"ice/pkg/ical"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLine bounds an NDJSON line
+const maxLine = 1 << 20
+
+// Row is a record read for import. Number counts records from 1, the CSV
+// header left out. Err is set when the row could not be parsed; reading
+// can go on with the next one.
+type Row struct {
+	Number int
+	Record Record
+	Err    error
+}
+
+// Reader reads records one at a time from a stream
+type Reader interface {
+	// Next returns the next row, io.EOF at the end of the input or an
+	// error when the input cannot be read any further
+	Next() (Row, error)
+}
+
+// NewReader starts reading r; for CSV it reads the header, which must
+// name the description and due_date columns
+func NewReader(f Format, r io.Reader) (Reader, error) {
+	switch f {
+	case CSV:
+		return newCSVReader(r)
+	case NDJSON:
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 0, 64*1024), maxLine)
+		return &ndjsonReader{sc: sc}, nil
+	case ICS:
+		return &icsReader{dec: ical.NewDecoder(r)}, nil
+	}
+	return &jsonReader{dec: json.NewDecoder(r)}, nil
+}
+
+// rawRecord is a record before its times are parsed, which may be dates
+type rawRecord struct {
+	ID          string `json:"id"`
+	ExternalID  string `json:"externalId"`
+	Description string `json:"description"`
+	DueDate     string `json:"dueDate"`
+	TimeZone    string `json:"timeZone"`
+	AllDay      bool   `json:"allDay"`
+	Recurrence  string `json:"recurrence"`
+	Completed   bool   `json:"completed"`
+	CompletedAt string `json:"completedAt"`
+}
+
+// record parses the times of raw; a due date given as a date is all-day.
+// Without an external ID the id of an export stands in for it, as the UID
+// does in iCalendar; the import matches it against the ids of the todos
+// too, so importing an export twice, here or elsewhere, creates nothing new.
+func (raw rawRecord) record() (Record, error) {
+	externalID := strings.TrimSpace(raw.ExternalID)
+	if externalID == "" {
+		externalID = strings.TrimSpace(raw.ID)
+	}
+	rec := Record{
+		ExternalID:  externalID,
+		Description: raw.Description,
+		TimeZone:    raw.TimeZone,
+		AllDay:      raw.AllDay,
+		Recurrence:  raw.Recurrence,
+		Completed:   raw.Completed,
+	}
+
+	if raw.DueDate != "" {
+		dueDate, date, err := parseTime(raw.DueDate)
+		if err != nil {
+			return Record{}, fmt.Errorf("dueDate: must be an RFC 3339 time or a 2006-01-02 date")
+		}
+		rec.DueDate = dueDate
+		rec.AllDay = rec.AllDay || date
+	}
+	if raw.CompletedAt != "" {
+		completedAt, err := time.Parse(time.RFC3339, raw.CompletedAt)
+		if err != nil {
+			return Record{}, fmt.Errorf("completedAt: must be an RFC 3339 time")
+		}
+		rec.CompletedAt = &completedAt
+	}
+	return rec, nil
+}
+
+type csvReader struct {
+	r *csv.Reader
+	// columns maps the fields of rawRecord to their column
+	columns map[string]int
+	n       int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("empty CSV, a header row is required")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		// external_id, externalId and "External ID" name the same column
+		name = strings.NewReplacer("_", "", "-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	for _, required := range []string{"description", "duedate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header lacks the %s column", required)
+		}
+	}
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (cr *csvReader) Next() (Row, error) {
+	fields, err := cr.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) || errors.Is(err, csv.ErrQuote) || errors.Is(err, csv.ErrBareQuote) {
+			return Row{}, err
+		}
+		// a wrong field count only affects this row
+		cr.n++
+		return Row{Number: cr.n, Err: err}, nil
+	}
+	cr.n++
+
+	field := func(name string) string {
+		if i, ok := cr.columns[name]; ok && i < len(fields) {
+			return unescapeFormula(strings.TrimSpace(fields[i]))
+		}
+		return ""
+	}
+	flag := func(name, column string) (bool, error) {
+		s := field(name)
+		if s == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return false, fmt.Errorf("%s: must be true or false", column)
+		}
+		return b, nil
+	}
+
+	raw := rawRecord{
+		ID:          field("id"),
+		ExternalID:  field("externalid"),
+		Description: field("description"),
+		DueDate:     field("duedate"),
+		TimeZone:    field("timezone"),
+		Recurrence:  field("recurrence"),
+		CompletedAt: field("completedat"),
+	}
+	if raw.AllDay, err = flag("allday", "all_day"); err != nil {
+		return Row{Number: cr.n, Err: err}, nil
+	}
+	if raw.Completed, err = flag("completed", "completed"); err != nil {
+		return Row{Number: cr.n, Err: err}, nil
+	}
+	rec, err := raw.record()
+	return Row{Number: cr.n, Record: rec, Err: err}, nil
+}
+
+type ndjsonReader struct {
+	sc *bufio.Scanner
+	n  int
+}
+
+func (nr *ndjsonReader) Next() (Row, error) {
+	for nr.sc.Scan() {
+		line := strings.TrimSpace(nr.sc.Text())
+		if line == "" {
+			continue
+		}
+		nr.n++
+		return decodeRow(nr.n, []byte(line)), nil
+	}
+	if err := nr.sc.Err(); err != nil {
+		return Row{}, err
+	}
+	return Row{}, io.EOF
+}
+
+type jsonReader struct {
+	dec     *json.Decoder
+	started bool
+	n       int
+}
+
+func (jr *jsonReader) Next() (Row, error) {
+	if !jr.started {
+		tok, err := jr.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return Row{}, fmt.Errorf("empty JSON, an array of todos is expected")
+		}
+		if err != nil {
+			return Row{}, err
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return Row{}, fmt.Errorf("a JSON array of todos is expected")
+		}
+		jr.started = true
+	}
+
+	if !jr.dec.More() {
+		if _, err := jr.dec.Token(); err != nil {
+			return Row{}, err
+		}
+		return Row{}, io.EOF
+	}
+
+	var msg json.RawMessage
+	if err := jr.dec.Decode(&msg); err != nil {
+		// the array itself is broken, nothing after this can be read
+		return Row{}, err
+	}
+	jr.n++
+	return decodeRow(jr.n, msg), nil
+}
+
+// decodeRow parses the JSON record numbered n
+func decodeRow(n int, data []byte) Row {
+	var raw rawRecord
+	if err := json.Unmarshal(data, &raw); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			err = fmt.Errorf("%s: must be a %s", typeErr.Field, typeErr.Type)
+		}
+		return Row{Number: n, Err: err}
+	}
+
+	rec, err := raw.record()
+	return Row{Number: n, Record: rec, Err: err}
+}
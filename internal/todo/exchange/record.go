@@ -0,0 +1,78 @@
+package exchange
+
+import (
+	"ice/internal/todo"
+	"time"
+)
+
+// Record is a todo as exported and imported. Imports only read the
+// editable fields, the external ID, falling back to the id, and the
+// completion state; the rest is set by the service.
+type Record struct {
+	ID          string     `json:"id,omitempty"`
+	ExternalID  string     `json:"externalId,omitempty" validate:"max=255"`
+	Description string     `json:"description" validate:"required"`
+	DueDate     time.Time  `json:"dueDate" validate:"required"`
+	TimeZone    string     `json:"timeZone,omitempty" validate:"omitempty,timezone"`
+	AllDay      bool       `json:"allDay"`
+	Recurrence  string     `json:"recurrence,omitempty" validate:"omitempty,rrule"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+	Version     int        `json:"version,omitempty"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// FromItem exports item, whose times are kept in the zone they are in
+func FromItem(item todo.TodoItem) Record {
+	return Record{
+		ID:          item.ID,
+		ExternalID:  item.ExternalID,
+		Description: item.Description,
+		DueDate:     item.DueDate,
+		TimeZone:    item.TimeZone,
+		AllDay:      item.AllDay,
+		Recurrence:  item.Recurrence,
+		Completed:   item.Completed,
+		CompletedAt: item.CompletedAt,
+		Version:     item.Version,
+		CreatedAt:   &item.CreatedAt,
+		UpdatedAt:   &item.UpdatedAt,
+	}
+}
+
+// Item is the todo to import for r, without an id
+func (r Record) Item() *todo.TodoItem {
+	item := &todo.TodoItem{
+		ExternalID:  r.ExternalID,
+		Description: r.Description,
+		DueDate:     r.DueDate,
+		TimeZone:    r.TimeZone,
+		AllDay:      r.AllDay,
+		Recurrence:  r.Recurrence,
+		Completed:   r.Completed || r.CompletedAt != nil,
+	}
+	if item.Completed {
+		completedAt := time.Now().UTC()
+		if r.CompletedAt != nil {
+			completedAt = r.CompletedAt.UTC()
+		}
+		// DATETIME keeps whole seconds
+		completedAt = completedAt.Truncate(time.Second)
+		item.CompletedAt = &completedAt
+	}
+	return item
+}
+
+// dateLayout is how all-day due dates are written to CSV, and a due date
+// read in that form is all-day
+const dateLayout = "2006-01-02"
+
+// parseTime reads an RFC 3339 time or a date, reporting which it was
+func parseTime(s string) (t time.Time, date bool, err error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, s)
+	return t, false, err
+}
@@ -0,0 +1,168 @@
+package exchange
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvColumns is the header of CSV exports
+var csvColumns = []string{
+	"id", "external_id", "description", "due_date", "time_zone", "all_day", "recurrence",
+	"completed", "completed_at", "version", "created_at", "updated_at",
+}
+
+// Writer writes records one at a time, never holding more than a buffer
+type Writer interface {
+	Write(r Record) error
+	// Flush hands the buffered output to the underlying writer
+	Flush() error
+	// Close completes the document and flushes it
+	Close() error
+}
+
+func NewWriter(f Format, w io.Writer) Writer {
+	switch f {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}
+	case NDJSON:
+		bw := bufio.NewWriter(w)
+		return &jsonWriter{w: bw, enc: json.NewEncoder(bw)}
+	case ICS:
+		return NewICSWriter(w, Event)
+	}
+	bw := bufio.NewWriter(w)
+	return &jsonWriter{w: bw, enc: json.NewEncoder(bw), array: true}
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func (cw *csvWriter) Write(r Record) error {
+	if !cw.header {
+		cw.header = true
+		if err := cw.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+
+	dueDate := formatTime(&r.DueDate)
+	if r.AllDay {
+		dueDate = r.DueDate.Format(dateLayout)
+	}
+	fields := []string{
+		r.ID, r.ExternalID, r.Description, dueDate, r.TimeZone, strconv.FormatBool(r.AllDay), r.Recurrence,
+		strconv.FormatBool(r.Completed), formatTime(r.CompletedAt), strconv.Itoa(r.Version),
+		formatTime(r.CreatedAt), formatTime(r.UpdatedAt),
+	}
+	for i, f := range fields {
+		fields[i] = escapeFormula(f)
+	}
+	return cw.w.Write(fields)
+}
+
+// formulaStarts are the characters that make a spreadsheet read a cell
+// as a formula
+const formulaStarts = "=+-@\t\r"
+
+// isFormula reports whether s, its leading apostrophes left out, would be
+// read as a formula
+func isFormula(s string) bool {
+	s = strings.TrimLeft(s, "'")
+	return s != "" && strings.ContainsRune(formulaStarts, rune(s[0]))
+}
+
+// escapeFormula prefixes a cell a spreadsheet would evaluate with an
+// apostrophe, which makes it text; a cell already starting with one gets
+// another, so unescapeFormula gives back the original
+func escapeFormula(s string) string {
+	if isFormula(s) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescapeFormula undoes escapeFormula
+func unescapeFormula(s string) string {
+	if strings.HasPrefix(s, "'") && isFormula(s) {
+		return s[1:]
+	}
+	return s
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// Close writes the header of an empty export
+func (cw *csvWriter) Close() error {
+	if !cw.header {
+		cw.header = true
+		if err := cw.w.Write(csvColumns); err != nil {
+			return err
+		}
+	}
+	return cw.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// jsonWriter writes NDJSON, or a JSON array with a record per line when
+// array is set
+type jsonWriter struct {
+	w     *bufio.Writer
+	enc   *json.Encoder
+	array bool
+	// n counts the records of the array
+	n int
+}
+
+func (jw *jsonWriter) Write(r Record) error {
+	if !jw.array {
+		return jw.enc.Encode(r)
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if jw.n == 0 {
+		sep = "[\n"
+	}
+	jw.n++
+	if _, err := jw.w.WriteString(sep); err != nil {
+		return err
+	}
+	_, err = jw.w.Write(b)
+	return err
+}
+
+func (jw *jsonWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.array {
+		end := "\n]\n"
+		if jw.n == 0 {
+			end = "[]\n"
+		}
+		if _, err := jw.w.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return jw.w.Flush()
+}
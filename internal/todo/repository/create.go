@@ -3,12 +3,72 @@ package repository
 import (
 	"context"
 	"ice/internal/todo"
+	"strings"
+	"time"
 )
 
+// insertChunk bounds the rows per INSERT, keeping statements well below
+// the placeholder limit and max_allowed_packet
+const insertChunk = 200
+
+const insertTodo = `INSERT INTO todos (id, external_id, description, due_date, time_zone, all_day, recurrence,
+	occurrence, completed, completed_at, version, created_at, updated_at) VALUES `
+
+const insertTodoValues = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 1, ?, ?)"
+
 func (r *Repository) Create(ctx context.Context, item *todo.TodoItem) error {
-	_, err := r.mysql.DB().ExecContext(ctx,
-		"INSERT INTO todos (id, description, due_date) VALUES (?, ?, ?)",
-		item.ID, item.Description, item.DueDate,
-	)
-	return err
+	return r.CreateBatch(ctx, []*todo.TodoItem{item})
+}
+
+// CreateBatch inserts items with multi-row inserts. A todo may be created
+// completed, e.g. when imported.
+func (r *Repository) CreateBatch(ctx context.Context, items []*todo.TodoItem) error {
+	now := timestamp()
+	if err := r.insert(ctx, items, now, ""); err != nil {
+		return err
+	}
+	created(items, now)
+	return nil
+}
+
+// insert writes items insertChunk rows per statement, each statement
+// ending in suffix
+func (r *Repository) insert(ctx context.Context, items []*todo.TodoItem, now time.Time, suffix string) error {
+	for start := 0; start < len(items); start += insertChunk {
+		chunk := items[start:min(start+insertChunk, len(items))]
+
+		values := make([]string, len(chunk))
+		args := make([]any, 0, len(chunk)*12)
+		for i, item := range chunk {
+			values[i] = insertTodoValues
+			args = append(args, insertArgs(item, now)...)
+		}
+
+		_, err := r.mysql.Writer(ctx).ExecContext(ctx, insertTodo+strings.Join(values, ", ")+suffix, args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// created sets what the insert gave items
+func created(items []*todo.TodoItem, now time.Time) {
+	for _, item := range items {
+		item.Version = 1
+		item.CreatedAt = now
+		item.UpdatedAt = now
+	}
+}
+
+func insertArgs(item *todo.TodoItem, now time.Time) []any {
+	// NULL keeps todos without an external ID out of its unique index
+	var externalID any
+	if item.ExternalID != "" {
+		externalID = item.ExternalID
+	}
+	return []any{
+		item.ID, externalID, item.Description, item.DueDate, item.TimeZone, item.AllDay, item.Recurrence,
+		item.Occurrence, item.Completed, item.CompletedAt, now, now,
+	}
 }
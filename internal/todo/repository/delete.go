@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"ice/internal/todo"
+	"time"
+)
+
+// Delete moves the todo to the trash at item.DeletedAt, with the same
+// version check as Update
+func (r *Repository) Delete(ctx context.Context, item *todo.TodoItem) error {
+	now := timestamp()
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`UPDATE todos
+		 SET deleted_at = ?, version = version + 1, updated_at = ?
+		 WHERE id = ? AND version = ? AND deleted_at IS NULL`,
+		item.DeletedAt, now, item.ID, item.Version,
+	)
+	if err != nil {
+		return err
+	}
+	return bumpVersion(res, item, now)
+}
+
+// Restore takes the todo out of the trash, with the same version check as
+// Update
+func (r *Repository) Restore(ctx context.Context, item *todo.TodoItem) error {
+	now := timestamp()
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`UPDATE todos
+		 SET deleted_at = NULL, version = version + 1, updated_at = ?
+		 WHERE id = ? AND version = ? AND deleted_at IS NOT NULL`,
+		now, item.ID, item.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := bumpVersion(res, item, now); err != nil {
+		return err
+	}
+	item.DeletedAt = nil
+	return nil
+}
+
+// FindPurgeable returns up to limit todos deleted before the given time,
+// oldest first. It may read from a replica, Purge rechecks the version.
+func (r *Repository) FindPurgeable(ctx context.Context, before time.Time, limit int) ([]todo.TodoItem, error) {
+	return queryTodos(ctx, r.mysql.Reader(ctx),
+		selectTodo+" WHERE deleted_at IS NOT NULL AND deleted_at < ? ORDER BY deleted_at LIMIT ?",
+		before, limit,
+	)
+}
+
+// Purge removes a todo from the trash for good, along with its reminders.
+// It returns todo.ErrVersionConflict when the todo was restored or changed
+// since item was read.
+func (r *Repository) Purge(ctx context.Context, item *todo.TodoItem) error {
+	db := r.mysql.Writer(ctx)
+	res, err := db.ExecContext(ctx,
+		"DELETE FROM todos WHERE id = ? AND version = ? AND deleted_at IS NOT NULL",
+		item.ID, item.Version,
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrVersionConflict
+	}
+
+	_, err = db.ExecContext(ctx, "DELETE FROM todo_reminders WHERE todo_id = ?", item.ID)
+	return err
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"ice/internal/todo"
+	"strings"
+)
+
+// ListAfter returns up to limit live todos with an id greater than
+// afterID, in id order, to page through all todos without holding a
+// query open between pages
+func (r *Repository) ListAfter(ctx context.Context, afterID string, limit int) ([]todo.TodoItem, error) {
+	return queryTodos(ctx, r.mysql.Reader(ctx),
+		selectTodo+" WHERE deleted_at IS NULL AND id > ? ORDER BY id LIMIT ?",
+		afterID, limit,
+	)
+}
+
+// FindExternalIDs maps those of the given external IDs that are taken, by
+// a live or deleted todo, to the id of that todo. An external ID equal to
+// the id of a todo counts as taken by it: a record exported without
+// external ID is imported with its id as one, so importing an export into
+// the instance it came from finds the originals. It reads the primary.
+func (r *Repository) FindExternalIDs(ctx context.Context, externalIDs []string) (map[string]string, error) {
+	if len(externalIDs) == 0 {
+		return make(map[string]string), nil
+	}
+	args := make([]any, 0, 2*len(externalIDs))
+	for range 2 {
+		for _, id := range externalIDs {
+			args = append(args, id)
+		}
+	}
+	in := placeholders(len(externalIDs))
+	holders, err := r.queryHolders(ctx,
+		"SELECT external_id, id FROM todos WHERE external_id IN ("+in+") OR id IN ("+in+")", args...)
+	if err != nil {
+		return nil, err
+	}
+	return takenBy(externalIDs, holders), nil
+}
+
+// CreateImported inserts items like CreateBatch, except that an item whose
+// external ID a todo took since FindExternalIDs, e.g. one of an import
+// running alongside, is skipped instead of failing the statement. The ids
+// of the skipped items are mapped to the todo holding their external ID.
+func (r *Repository) CreateImported(ctx context.Context, items []*todo.TodoItem) (map[string]string, error) {
+	now := timestamp()
+	// the no-op update turns a duplicate external ID into a skipped row,
+	// without hiding the other errors INSERT IGNORE would
+	if err := r.insert(ctx, items, now, " ON DUPLICATE KEY UPDATE id = id"); err != nil {
+		return nil, err
+	}
+
+	var externalIDs []string
+	for _, item := range items {
+		if item.ExternalID != "" {
+			externalIDs = append(externalIDs, item.ExternalID)
+		}
+	}
+	// a locking read sees the rows other transactions committed, which
+	// the snapshot of a plain one would not
+	found := make(map[string]string)
+	if len(externalIDs) > 0 {
+		args := make([]any, len(externalIDs))
+		for i, id := range externalIDs {
+			args[i] = id
+		}
+		holders, err := r.queryHolders(ctx,
+			"SELECT external_id, id FROM todos WHERE external_id IN ("+placeholders(len(args))+") FOR SHARE", args...)
+		if err != nil {
+			return nil, err
+		}
+		found = takenBy(externalIDs, holders)
+	}
+
+	skipped := make(map[string]string)
+	inserted := make([]*todo.TodoItem, 0, len(items))
+	for _, item := range items {
+		if id, ok := found[item.ExternalID]; ok && item.ExternalID != "" && id != item.ID {
+			skipped[item.ID] = id
+			continue
+		}
+		inserted = append(inserted, item)
+	}
+	created(inserted, now)
+	return skipped, nil
+}
+
+// holder is a todo holding an external ID, or its id standing in for one
+type holder struct {
+	externalID sql.NullString
+	id         string
+}
+
+func (r *Repository) queryHolders(ctx context.Context, query string, args ...any) ([]holder, error) {
+	rows, err := r.mysql.Writer(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var holders []holder
+	for rows.Next() {
+		var h holder
+		if err := rows.Scan(&h.externalID, &h.id); err != nil {
+			return nil, err
+		}
+		holders = append(holders, h)
+	}
+	return holders, rows.Err()
+}
+
+// takenBy maps each of externalIDs held by one of holders to the id of
+// that todo. A todo holding it as external ID wins over one whose id it is.
+func takenBy(externalIDs []string, holders []holder) map[string]string {
+	wanted := make(map[string]bool, len(externalIDs))
+	for _, id := range externalIDs {
+		wanted[id] = true
+	}
+	found := make(map[string]string)
+	for _, h := range holders {
+		if h.externalID.Valid && wanted[h.externalID.String] {
+			found[h.externalID.String] = h.id
+		}
+	}
+	for _, h := range holders {
+		if _, ok := found[h.id]; !ok && wanted[h.id] {
+			found[h.id] = h.id
+		}
+	}
+	return found
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+)
+
+func TestTakenBy(t *testing.T) {
+	external := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+
+	tests := []struct {
+		name        string
+		externalIDs []string
+		holders     []holder
+		want        map[string]string
+	}{
+		{
+			name:        "nothing taken",
+			externalIDs: []string{"legacy-7"},
+			want:        map[string]string{},
+		},
+		{
+			name:        "external id",
+			externalIDs: []string{"legacy-7", "legacy-8"},
+			holders:     []holder{{externalID: external("legacy-7"), id: "t1"}},
+			want:        map[string]string{"legacy-7": "t1"},
+		},
+		{
+			name:        "re-import into the same instance",
+			externalIDs: []string{"t1", "t2"},
+			holders:     []holder{{id: "t1"}, {externalID: external("legacy-7"), id: "t2"}},
+			want:        map[string]string{"t1": "t1", "t2": "t2"},
+		},
+		{
+			name:        "external id wins over id",
+			externalIDs: []string{"t1"},
+			holders:     []holder{{id: "t1"}, {externalID: external("t1"), id: "t9"}},
+			want:        map[string]string{"t1": "t9"},
+		},
+		{
+			name:        "external id of a holder found by id",
+			externalIDs: []string{"t1"},
+			holders:     []holder{{externalID: external("legacy-7"), id: "t1"}},
+			want:        map[string]string{"t1": "t1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := takenBy(tt.externalIDs, tt.holders); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("takenBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"ice/internal/adapter/mysql"
+	"ice/internal/todo"
+)
+
+const selectTodo = `SELECT id, COALESCE(external_id, ''), description, due_date, time_zone, all_day, recurrence, occurrence,
+	completed, completed_at, version, created_at, updated_at, deleted_at
+	FROM todos`
+
+// Get returns todo.ErrNotFound when no live todo has the given id, todos
+// in the trash included. It reads the primary: the version read becomes
+// the ETag of conditional writes, and a lagging replica would hand out one
+// that no longer matches, failing every retry with 412.
+func (r *Repository) Get(ctx context.Context, id string) (*todo.TodoItem, error) {
+	return scanTodo(ctx, r.mysql.Writer(ctx), todo.ErrNotFound,
+		selectTodo+" WHERE id = ? AND deleted_at IS NULL", id)
+}
+
+// GetForUpdate is Get reading from the primary and locking the row until
+// the transaction bound to ctx ends
+func (r *Repository) GetForUpdate(ctx context.Context, id string) (*todo.TodoItem, error) {
+	return scanTodo(ctx, r.mysql.Writer(ctx), todo.ErrNotFound,
+		selectTodo+" WHERE id = ? AND deleted_at IS NULL FOR UPDATE", id)
+}
+
+// GetDeletedForUpdate is GetForUpdate for a todo in the trash, returning
+// todo.ErrNotInTrash when there is none with the given id
+func (r *Repository) GetDeletedForUpdate(ctx context.Context, id string) (*todo.TodoItem, error) {
+	return scanTodo(ctx, r.mysql.Writer(ctx), todo.ErrNotInTrash,
+		selectTodo+" WHERE id = ? AND deleted_at IS NOT NULL FOR UPDATE", id)
+}
+
+// ListDeleted returns a page of the trash, most recently deleted first. It
+// reads the primary like Get: a todo just deleted must be listed, and the
+// versions listed are the ones a restore is made against.
+func (r *Repository) ListDeleted(ctx context.Context, limit, offset int) ([]todo.TodoItem, error) {
+	return queryTodos(ctx, r.mysql.Writer(ctx),
+		selectTodo+" WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id LIMIT ? OFFSET ?",
+		limit, offset,
+	)
+}
+
+func scanTodo(ctx context.Context, db mysql.Executor, notFound error, query string, args ...any) (*todo.TodoItem, error) {
+	var item todo.TodoItem
+	err := scanRow(db.QueryRowContext(ctx, query, args...), &item)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, notFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func queryTodos(ctx context.Context, db mysql.Executor, query string, args ...any) ([]todo.TodoItem, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []todo.TodoItem{}
+	for rows.Next() {
+		var item todo.TodoItem
+		if err := scanRow(rows, &item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// scanRow reads the columns of selectTodo
+func scanRow(row interface{ Scan(dest ...any) error }, item *todo.TodoItem) error {
+	return row.Scan(
+		&item.ID, &item.ExternalID, &item.Description, &item.DueDate, &item.TimeZone, &item.AllDay, &item.Recurrence,
+		&item.Occurrence, &item.Completed, &item.CompletedAt,
+		&item.Version, &item.CreatedAt, &item.UpdatedAt, &item.DeletedAt,
+	)
+}
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"database/sql"
 	"ice/internal/adapter/mysql"
+	"ice/internal/todo"
+	"time"
 )
 
 type Repository struct {
@@ -11,3 +14,23 @@ type Repository struct {
 func NewRepository(mysql *mysql.MySQL) *Repository {
 	return &Repository{mysql: mysql}
 }
+
+// timestamp is the current time at the precision of a DATETIME column
+func timestamp() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+// bumpVersion reflects a versioned write in item, a write matching no row
+// means the version moved on or the todo is gone
+func bumpVersion(res sql.Result, item *todo.TodoItem, now time.Time) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrVersionConflict
+	}
+	item.Version++
+	item.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"ice/internal/todo"
+)
+
+// Update stores the editable fields of item if it is still at
+// item.Version, and returns todo.ErrVersionConflict otherwise. When the
+// due date moves the reminders already sent are forgotten, so the todo is
+// reminded again for its new date; both statements must run in the same
+// transaction.
+func (r *Repository) Update(ctx context.Context, item *todo.TodoItem) error {
+	db := r.mysql.Writer(ctx)
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM todo_reminders
+		 WHERE todo_id = ? AND EXISTS (
+		   SELECT 1 FROM todos WHERE id = ? AND version = ? AND due_date <> ?
+		 )`,
+		item.ID, item.ID, item.Version, item.DueDate,
+	)
+	if err != nil {
+		return err
+	}
+
+	now := timestamp()
+	res, err := db.ExecContext(ctx,
+		`UPDATE todos
+		 SET description = ?, due_date = ?, time_zone = ?, all_day = ?, recurrence = ?,
+		     version = version + 1, updated_at = ?
+		 WHERE id = ? AND version = ?`,
+		item.Description, item.DueDate, item.TimeZone, item.AllDay, item.Recurrence,
+		now, item.ID, item.Version,
+	)
+	if err != nil {
+		return err
+	}
+	return bumpVersion(res, item, now)
+}
+
+// Complete marks the todo completed at item.CompletedAt, with the same
+// version check as Update
+func (r *Repository) Complete(ctx context.Context, item *todo.TodoItem) error {
+	now := timestamp()
+	res, err := r.mysql.Writer(ctx).ExecContext(ctx,
+		`UPDATE todos
+		 SET completed = TRUE, completed_at = ?, version = version + 1, updated_at = ?
+		 WHERE id = ? AND version = ?`,
+		item.CompletedAt, now, item.ID, item.Version,
+	)
+	if err != nil {
+		return err
+	}
+	return bumpVersion(res, item, now)
+}
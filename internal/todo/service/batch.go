@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/port"
+	"ice/internal/todo"
+)
+
+// errRollback aborts the transaction of an all-or-nothing batch whose
+// failed operation has already been reported
+var errRollback = errors.New("batch rolled back")
+
+var errUnknownOperation = errors.New("unknown operation")
+
+// Batch runs ops in a single transaction and returns the result of each,
+// nil on success with its Item filled. New todos are inserted together,
+// and the audit entries and events of all operations are written with one
+// insert each.
+//
+// With atomic set the first failing operation rolls the whole batch back
+// and every other operation reports todo.ErrBatchAborted; otherwise failed
+// operations are skipped and the rest is committed. The returned error is
+// set when the batch as a whole failed, e.g. on a database error, in which
+// case nothing was written.
+func (s *Service) Batch(ctx context.Context, ops []todo.Operation, atomic bool) ([]error, error) {
+	results := make([]error, len(ops))
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		var creates []*todo.TodoItem
+		for i, op := range ops {
+			if op.Kind != todo.OpCreate {
+				continue
+			}
+			if err := prepare(op.Item); err != nil {
+				results[i] = err
+				if atomic {
+					return errRollback
+				}
+				continue
+			}
+			creates = append(creates, op.Item)
+		}
+		if err := s.repo.CreateBatch(ctx, creates); err != nil {
+			return err
+		}
+
+		var (
+			entries []*audit.Entry
+			events  []port.OutboxEvent
+		)
+		for i, op := range ops {
+			if results[i] != nil {
+				continue
+			}
+
+			var (
+				entry audit.Entry
+				e     event.Event
+			)
+			switch op.Kind {
+			case todo.OpCreate:
+				entry = audit.NewEntry(ctx, audit.ActionCreate, nil, op.Item)
+				e = event.NewTodoCreated(op.Item)
+
+			case todo.OpUpdate:
+				before, err := s.update(ctx, op.Item)
+				if err != nil {
+					results[i] = err
+					break
+				}
+				entry = audit.NewEntry(ctx, audit.ActionUpdate, before, op.Item)
+				e = event.NewTodoUpdated(op.Item)
+
+			case todo.OpDelete:
+				before, after, err := s.delete(ctx, op.Item.ID, op.Item.Version)
+				if err != nil {
+					results[i] = err
+					break
+				}
+				*op.Item = *after
+				entry = audit.NewEntry(ctx, audit.ActionDelete, before, after)
+				e = event.NewTodoDeleted(after)
+
+			default:
+				results[i] = fmt.Errorf("%w %q", errUnknownOperation, op.Kind)
+			}
+
+			if err := results[i]; err != nil {
+				if !operationError(err) {
+					return err
+				}
+				if atomic {
+					return errRollback
+				}
+				continue
+			}
+			entries = append(entries, &entry)
+			events = append(events, port.OutboxEvent{AggregateID: op.Item.ID, Event: e})
+		}
+
+		if err := s.audit.AppendBatch(ctx, entries); err != nil {
+			return err
+		}
+		return s.outbox.WriteBatch(ctx, event.TopicTodo, events)
+	})
+
+	switch {
+	case errors.Is(err, errRollback):
+		for i := range results {
+			if results[i] == nil {
+				results[i] = todo.ErrBatchAborted
+			}
+		}
+		return results, nil
+	case err != nil:
+		return nil, err
+	}
+	return results, nil
+}
+
+// operationError reports whether err concerns a single operation of a
+// batch rather than the batch as a whole
+func operationError(err error) bool {
+	return errors.Is(err, todo.ErrNotFound) || errors.Is(err, todo.ErrVersionConflict) ||
+		errors.Is(err, errUnknownOperation)
+}
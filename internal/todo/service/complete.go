@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/todo"
+	"ice/pkg/recurrence"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompleteTodo completes the todo and, if its rule has another occurrence,
+// creates the next todo of the series in the same transaction. The row
+// lock makes concurrent completions create a single next occurrence.
+func (s *Service) CompleteTodo(ctx context.Context, id string, version int) (*todo.TodoItem, *todo.TodoItem, error) {
+	var completed, next *todo.TodoItem
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		item, err := s.repo.GetForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(item, version); err != nil {
+			return err
+		}
+		if item.Completed {
+			return todo.ErrAlreadyCompleted
+		}
+
+		before := *item
+		// DATETIME keeps whole seconds
+		now := time.Now().UTC().Truncate(time.Second)
+		item.Completed = true
+		item.CompletedAt = &now
+		if err := s.repo.Complete(ctx, item); err != nil {
+			return err
+		}
+		if err := s.record(ctx, audit.ActionComplete, &before, item); err != nil {
+			return err
+		}
+
+		next, err = nextOccurrence(item)
+		if err != nil {
+			return err
+		}
+		if next != nil {
+			if err := s.repo.Create(ctx, next); err != nil {
+				return err
+			}
+			if err := s.record(ctx, audit.ActionCreate, nil, next); err != nil {
+				return err
+			}
+			if err := s.outbox.Write(ctx, event.TopicTodo, next.ID, event.NewTodoCreated(next)); err != nil {
+				return err
+			}
+		}
+
+		completed = item
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoCompleted(item, next))
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return completed, next, nil
+}
+
+// nextOccurrence builds the todo following item in its series, nil when
+// item does not recur or its rule has ended
+func nextOccurrence(item *todo.TodoItem) (*todo.TodoItem, error) {
+	if item.Recurrence == "" {
+		return nil, nil
+	}
+	rule, err := recurrence.Parse(item.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	// occurrences keep their wall clock time in the todo's zone across
+	// daylight saving changes
+	due, ok := rule.Next(item.DueDate.In(item.Location()), item.Occurrence)
+	if !ok {
+		return nil, nil
+	}
+	return &todo.TodoItem{
+		ID:          uuid.New().String(),
+		Description: item.Description,
+		DueDate:     due.UTC(),
+		TimeZone:    item.TimeZone,
+		AllDay:      item.AllDay,
+		Recurrence:  item.Recurrence,
+		Occurrence:  item.Occurrence + 1,
+	}, nil
+}
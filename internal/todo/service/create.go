@@ -2,22 +2,70 @@ package service
 
 import (
 	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
 	"ice/internal/port"
 	"ice/internal/todo"
+	"ice/pkg/recurrence"
 )
 
 type Service struct {
 	repo   port.TodoRepository
 	outbox port.OutboxWriter
+	tx     port.TxManager
+	audit  port.AuditRepository
 }
 
-func NewService(repo port.TodoRepository, outbox port.OutboxWriter) *Service {
-	return &Service{repo: repo, outbox: outbox}
+func NewService(repo port.TodoRepository, outbox port.OutboxWriter, tx port.TxManager, audit port.AuditRepository) *Service {
+	return &Service{repo: repo, outbox: outbox, tx: tx, audit: audit}
 }
 
 func (s *Service) CreateTodo(ctx context.Context, item *todo.TodoItem) error {
-	if err := s.repo.Create(ctx, item); err != nil {
+	if err := prepare(item); err != nil {
 		return err
 	}
-	return s.outbox.Write(ctx, "todo_stream", item)
+
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, item); err != nil {
+			return err
+		}
+		if err := s.record(ctx, audit.ActionCreate, nil, item); err != nil {
+			return err
+		}
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoCreated(item))
+	})
+}
+
+// record appends the audit entry of a mutation from before to after to
+// the transaction bound to ctx
+func (s *Service) record(ctx context.Context, action audit.Action, before, after *todo.TodoItem) error {
+	entry := audit.NewEntry(ctx, action, before, after)
+	return s.audit.Append(ctx, &entry)
+}
+
+// prepare normalizes a new todo for storage
+func prepare(item *todo.TodoItem) error {
+	rule, err := normalizeRecurrence(item.Recurrence)
+	if err != nil {
+		return err
+	}
+	item.Recurrence = rule
+	item.NormalizeDueDate()
+	if item.Occurrence <= 0 {
+		item.Occurrence = 1
+	}
+	return nil
+}
+
+// normalizeRecurrence stores rules in canonical form so equal rules
+// compare equal
+func normalizeRecurrence(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	rule, err := recurrence.Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/todo"
+	"time"
+)
+
+// DeleteTodo moves the todo to the trash, where it can be restored until
+// it is purged
+func (s *Service) DeleteTodo(ctx context.Context, id string, version int) error {
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		before, item, err := s.delete(ctx, id, version)
+		if err != nil {
+			return err
+		}
+		if err := s.record(ctx, audit.ActionDelete, before, item); err != nil {
+			return err
+		}
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoDeleted(item))
+	})
+}
+
+// delete moves the todo to the trash in the transaction bound to ctx and
+// returns it as it was before and after
+func (s *Service) delete(ctx context.Context, id string, version int) (before, after *todo.TodoItem, err error) {
+	item, err := s.repo.GetForUpdate(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkVersion(item, version); err != nil {
+		return nil, nil, err
+	}
+
+	stored := *item
+	// DATETIME keeps whole seconds
+	now := time.Now().UTC().Truncate(time.Second)
+	item.DeletedAt = &now
+	if err := s.repo.Delete(ctx, item); err != nil {
+		return nil, nil, err
+	}
+	return &stored, item, nil
+}
+
+// checkVersion fails with todo.ErrVersionConflict when the caller expects
+// another version than the stored one; zero expects any
+func checkVersion(item *todo.TodoItem, version int) error {
+	if version != 0 && item.Version != version {
+		return todo.ErrVersionConflict
+	}
+	return nil
+}
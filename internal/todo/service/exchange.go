@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/port"
+	"ice/internal/todo"
+)
+
+// exportPageSize is the number of todos fetched per query while exporting
+const exportPageSize = 500
+
+func (s *Service) ExportTodos(ctx context.Context, fn func(item *todo.TodoItem) error) error {
+	after := ""
+	for {
+		items, err := s.repo.ListAfter(ctx, after, exportPageSize)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if err := fn(&items[i]); err != nil {
+				return err
+			}
+		}
+		if len(items) < exportPageSize {
+			return nil
+		}
+		after = items[len(items)-1].ID
+	}
+}
+
+// ImportTodos creates the items in one transaction, like a batch of
+// creates. Items whose external ID is already taken are left out and get
+// the id of the todo holding it in duplicates; with dryRun the lookup is
+// all that runs. An import running alongside may take an external ID
+// between the lookup and the insert: the item is then skipped by the
+// insert and reported as a duplicate too, rather than failing the import.
+func (s *Service) ImportTodos(ctx context.Context, items []*todo.TodoItem, dryRun bool) ([]string, error) {
+	duplicates := make([]string, len(items))
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		var externalIDs []string
+		for _, item := range items {
+			if item.ExternalID != "" {
+				externalIDs = append(externalIDs, item.ExternalID)
+			}
+		}
+		taken, err := s.repo.FindExternalIDs(ctx, externalIDs)
+		if err != nil {
+			return err
+		}
+
+		var creates []*todo.TodoItem
+		var indexes []int
+		for i, item := range items {
+			if id, ok := taken[item.ExternalID]; ok && item.ExternalID != "" {
+				duplicates[i] = id
+				continue
+			}
+			if err := prepare(item); err != nil {
+				return err
+			}
+			creates = append(creates, item)
+			indexes = append(indexes, i)
+		}
+		if dryRun {
+			return nil
+		}
+
+		skipped, err := s.repo.CreateImported(ctx, creates)
+		if err != nil {
+			return err
+		}
+		entries := make([]*audit.Entry, 0, len(creates))
+		events := make([]port.OutboxEvent, 0, len(creates))
+		for i, item := range creates {
+			if id, ok := skipped[item.ID]; ok {
+				duplicates[indexes[i]] = id
+				continue
+			}
+			entry := audit.NewEntry(ctx, audit.ActionCreate, nil, item)
+			entries = append(entries, &entry)
+			events = append(events, port.OutboxEvent{AggregateID: item.ID, Event: event.NewTodoCreated(item)})
+		}
+		if err := s.audit.AppendBatch(ctx, entries); err != nil {
+			return err
+		}
+		return s.outbox.WriteBatch(ctx, event.TopicTodo, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return duplicates, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/todo"
+)
+
+func (s *Service) GetTodo(ctx context.Context, id string) (*todo.TodoItem, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// History returns a page of the audit log of a todo, oldest first. It
+// outlives the todo, so purged todos still have one; todo.ErrNotFound
+// means no todo with that id was ever recorded.
+func (s *Service) History(ctx context.Context, id string, limit, offset int) ([]audit.Entry, error) {
+	entries, err := s.audit.List(ctx, id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 && offset == 0 {
+		return nil, todo.ErrNotFound
+	}
+	return entries, nil
+}
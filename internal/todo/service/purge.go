@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"ice/config"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/port"
+	"ice/internal/todo"
+	"ice/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// purgeLockName is the MySQL lock electing the instance that purges
+const purgeLockName = "ice.todo.purger"
+
+// purgeActor is the actor of purges in the audit log
+const purgeActor = "system:purger"
+
+// Purger removes todos that have been in the trash for longer than the
+// retention period
+type Purger struct {
+	repo   port.TodoRepository
+	outbox port.OutboxWriter
+	tx     port.TxManager
+	locker port.Locker
+	audit  port.AuditRepository
+	cfg    config.TrashConfig
+}
+
+type PurgerDependencies struct {
+	Repo   port.TodoRepository
+	Outbox port.OutboxWriter
+	Tx     port.TxManager
+	Locker port.Locker
+	Audit  port.AuditRepository
+}
+
+func NewPurger(deps PurgerDependencies, cfg config.TrashConfig) *Purger {
+	if cfg.PurgeInterval <= 0 {
+		cfg.PurgeInterval = time.Hour
+	}
+	if cfg.PurgeBatchSize <= 0 {
+		cfg.PurgeBatchSize = 100
+	}
+
+	return &Purger{
+		repo:   deps.Repo,
+		outbox: deps.Outbox,
+		tx:     deps.Tx,
+		locker: deps.Locker,
+		audit:  deps.Audit,
+		cfg:    cfg,
+	}
+}
+
+// Purge removes the expired todos in batches of PurgeBatchSize and
+// returns how many were removed. It goes on while batches come back full,
+// and stops early when a whole batch was skipped, which happens while the
+// replica it lists from still shows todos already purged. Only one
+// instance purges at a time; the others skip the round.
+func (p *Purger) Purge(ctx context.Context) (int, error) {
+	release, ok, err := p.locker.Lock(ctx, purgeLockName, 0)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	defer release()
+
+	purged := 0
+	for ctx.Err() == nil {
+		items, err := p.repo.FindPurgeable(ctx, time.Now().UTC().Add(-p.cfg.Retention), p.cfg.PurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		batch := 0
+		for i := range items {
+			ok, err := p.purge(ctx, &items[i])
+			if err != nil {
+				return purged + batch, err
+			}
+			if ok {
+				batch++
+			}
+		}
+		purged += batch
+
+		if len(items) < p.cfg.PurgeBatchSize || batch == 0 {
+			break
+		}
+	}
+	return purged, nil
+}
+
+// purge removes the todo and writes its event in one transaction. A todo
+// restored since it was listed is skipped.
+func (p *Purger) purge(ctx context.Context, item *todo.TodoItem) (bool, error) {
+	err := p.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := p.repo.Purge(ctx, item); err != nil {
+			return err
+		}
+		entry := audit.NewEntry(audit.WithSource(ctx, audit.Source{Actor: purgeActor, Verified: true}), audit.ActionPurge, item, nil)
+		if err := p.audit.Append(ctx, &entry); err != nil {
+			return err
+		}
+		return p.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoPurged(item))
+	})
+	if errors.Is(err, todo.ErrVersionConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to purge todo %s: %w", item.ID, err)
+	}
+	return true, nil
+}
+
+// PurgeJob is the handle of a running purger
+type PurgeJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Start purges every PurgeInterval until ctx is cancelled or Stop is
+// called
+func (p *Purger) Start(ctx context.Context) *PurgeJob {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &PurgeJob{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(job.done)
+
+		ticker := time.NewTicker(p.cfg.PurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Get().Info("Trash purger stopped")
+				return
+			case <-ticker.C:
+			}
+
+			purged, err := p.Purge(ctx)
+			if err != nil && ctx.Err() == nil {
+				logger.Get().Error("trash purge failed", zap.Error(err))
+			}
+			if purged > 0 {
+				logger.Get().Info("todos purged from trash", zap.Int("count", purged))
+			}
+		}
+	}()
+
+	return job
+}
+
+// Stop cancels the purger and waits for the running purge to return
+func (job *PurgeJob) Stop(ctx context.Context) error {
+	job.cancel()
+
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/todo"
+)
+
+// RestoreTodo takes the todo out of the trash as it was when deleted
+func (s *Service) RestoreTodo(ctx context.Context, id string, version int) (*todo.TodoItem, error) {
+	var restored *todo.TodoItem
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		item, err := s.repo.GetDeletedForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(item, version); err != nil {
+			return err
+		}
+
+		before := *item
+		if err := s.repo.Restore(ctx, item); err != nil {
+			return err
+		}
+		if err := s.record(ctx, audit.ActionRestore, &before, item); err != nil {
+			return err
+		}
+		restored = item
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoRestored(item))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+func (s *Service) ListTrash(ctx context.Context, limit, offset int) ([]todo.TodoItem, error) {
+	return s.repo.ListDeleted(ctx, limit, offset)
+}
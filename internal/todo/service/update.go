@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"ice/internal/audit"
+	"ice/internal/event"
+	"ice/internal/todo"
+)
+
+func (s *Service) UpdateTodo(ctx context.Context, item *todo.TodoItem) error {
+	return s.tx.WithTx(ctx, func(ctx context.Context) error {
+		before, err := s.update(ctx, item)
+		if err != nil {
+			return err
+		}
+		if err := s.record(ctx, audit.ActionUpdate, before, item); err != nil {
+			return err
+		}
+		return s.outbox.Write(ctx, event.TopicTodo, item.ID, event.NewTodoUpdated(item))
+	})
+}
+
+// update applies the editable fields of item to the stored todo in the
+// transaction bound to ctx and fills item with the result. It returns the
+// todo as it was before.
+func (s *Service) update(ctx context.Context, item *todo.TodoItem) (*todo.TodoItem, error) {
+	rule, err := normalizeRecurrence(item.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.repo.GetForUpdate(ctx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersion(stored, item.Version); err != nil {
+		return nil, err
+	}
+
+	before := *stored
+	stored.Description = item.Description
+	stored.DueDate = item.DueDate
+	stored.TimeZone = item.TimeZone
+	stored.AllDay = item.AllDay
+	stored.Recurrence = rule
+	stored.NormalizeDueDate()
+	if err := s.repo.Update(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	*item = *stored
+	return &before, nil
+}
@@ -33,6 +33,20 @@ func NewValidationError(message string) *AppError {
 	}
 }
 
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusUnauthorized,
+		Message: message,
+	}
+}
+
+func NewRequestEntityTooLargeError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusRequestEntityTooLarge,
+		Message: message,
+	}
+}
+
 func NewInternalError(message string, err error) *AppError {
 	return &AppError{
 		Code:    http.StatusInternalServerError,
@@ -48,3 +62,23 @@ func NewNotFoundError(message string) *AppError {
 	}
 }
 
+func NewConflictError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: message,
+	}
+}
+
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusPreconditionFailed,
+		Message: message,
+	}
+}
+
+func NewPreconditionRequiredError(message string) *AppError {
+	return &AppError{
+		Code:    http.StatusPreconditionRequired,
+		Message: message,
+	}
+}
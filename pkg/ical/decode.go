@@ -0,0 +1,232 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Bounds of what a calendar may hold, so a hostile file cannot exhaust
+// the stack or memory: a content line once unfolded, the depth of nested
+// components below a calendar (VEVENT, VALARM, ...), and the properties
+// and nested components of each
+const (
+	maxLine       = 1 << 20
+	maxDepth      = 8
+	maxProps      = 1000
+	maxComponents = 100
+)
+
+// Decoder reads the components of one or more VCALENDARs in turn
+type Decoder struct {
+	sc *bufio.Scanner
+	// lineNo is the number of the last physical line read
+	lineNo int
+	// pending is a physical line read ahead while unfolding
+	pending    *string
+	inCalendar bool
+	seen       bool
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLine)
+	return &Decoder{sc: sc}
+}
+
+// Next returns the next component of the calendar, such as a VEVENT, with
+// the components nested in it, or io.EOF after the last one. Properties of
+// the calendar itself are skipped.
+func (d *Decoder) Next() (*Component, error) {
+	for {
+		p, n, err := d.prop()
+		if errors.Is(err, io.EOF) {
+			switch {
+			case d.inCalendar:
+				return nil, fmt.Errorf("line %d: missing END:VCALENDAR", d.lineNo)
+			case !d.seen:
+				return nil, fmt.Errorf("no VCALENDAR found")
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		value := strings.ToUpper(p.Value)
+		switch {
+		case !d.inCalendar:
+			if p.Name != "BEGIN" || value != "VCALENDAR" {
+				return nil, fmt.Errorf("line %d: expected BEGIN:VCALENDAR", n)
+			}
+			d.inCalendar, d.seen = true, true
+		case p.Name == "END" && value == "VCALENDAR":
+			d.inCalendar = false
+		case p.Name == "BEGIN":
+			return d.component(value, 1)
+		case p.Name == "END":
+			return nil, fmt.Errorf("line %d: unexpected END:%s", n, p.Value)
+		}
+	}
+}
+
+// component reads the block opened by BEGIN:name up to its END, depth
+// being 1 for a component of the calendar itself
+func (d *Decoder) component(name string, depth int) (*Component, error) {
+	c := &Component{Name: name}
+	for {
+		p, n, err := d.prop()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("line %d: missing END:%s", d.lineNo, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			if depth >= maxDepth {
+				return nil, fmt.Errorf("line %d: components nested deeper than %d", n, maxDepth)
+			}
+			if len(c.Components) >= maxComponents {
+				return nil, fmt.Errorf("line %d: %s holds more than %d components", n, name, maxComponents)
+			}
+			child, err := d.component(strings.ToUpper(p.Value), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			c.Components = append(c.Components, child)
+		case "END":
+			if strings.ToUpper(p.Value) != name {
+				return nil, fmt.Errorf("line %d: END:%s does not close %s", n, p.Value, name)
+			}
+			return c, nil
+		default:
+			if len(c.Props) >= maxProps {
+				return nil, fmt.Errorf("line %d: %s has more than %d properties", n, name, maxProps)
+			}
+			c.Props = append(c.Props, p)
+		}
+	}
+}
+
+// prop reads the next content line and the number of its first line
+func (d *Decoder) prop() (Property, int, error) {
+	line, n, err := d.unfold()
+	if err != nil {
+		return Property{}, 0, err
+	}
+	p, err := parseLine(line)
+	if err != nil {
+		return Property{}, 0, fmt.Errorf("line %d: %w", n, err)
+	}
+	return p, n, nil
+}
+
+// unfold joins a line with the continuation lines after it, skipping
+// blank lines
+func (d *Decoder) unfold() (string, int, error) {
+	var line string
+	for line == "" {
+		next, err := d.physical()
+		if err != nil {
+			return "", 0, err
+		}
+		line = next
+	}
+	n := d.lineNo
+
+	for {
+		next, err := d.physical()
+		if errors.Is(err, io.EOF) {
+			return line, n, nil
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		if next == "" || (next[0] != ' ' && next[0] != '\t') {
+			d.pending = &next
+			return line, n, nil
+		}
+		if len(line)+len(next) > maxLine {
+			return "", 0, fmt.Errorf("line %d: content line too long", n)
+		}
+		line += next[1:]
+	}
+}
+
+// physical returns the next line without its line ending
+func (d *Decoder) physical() (string, error) {
+	if d.pending != nil {
+		line := *d.pending
+		d.pending = nil
+		return line, nil
+	}
+	if !d.sc.Scan() {
+		if err := d.sc.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	d.lineNo++
+	return strings.TrimSuffix(d.sc.Text(), "\r"), nil
+}
+
+// parseLine splits name;param=value;...:value
+func parseLine(s string) (Property, error) {
+	i := strings.IndexAny(s, ";:")
+	if i <= 0 {
+		return Property{}, fmt.Errorf("malformed content line")
+	}
+	p := Property{Name: strings.ToUpper(s[:i])}
+
+	for s[i] == ';' {
+		s = s[i+1:]
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return Property{}, fmt.Errorf("malformed parameter of %s", p.Name)
+		}
+		name := strings.ToUpper(s[:eq])
+
+		// a list of values, each either quoted or up to the next delimiter
+		var value strings.Builder
+		j := eq + 1
+		for {
+			if j < len(s) && s[j] == '"' {
+				end := strings.IndexByte(s[j+1:], '"')
+				if end < 0 {
+					return Property{}, fmt.Errorf("unterminated quote in %s", p.Name)
+				}
+				value.WriteString(s[j+1 : j+1+end])
+				j += end + 2
+			} else {
+				end := strings.IndexAny(s[j:], ",;:")
+				if end < 0 {
+					return Property{}, fmt.Errorf("malformed parameter of %s", p.Name)
+				}
+				value.WriteString(s[j : j+end])
+				j += end
+			}
+			if j < len(s) && s[j] == ',' {
+				value.WriteByte(',')
+				j++
+				continue
+			}
+			break
+		}
+		if j >= len(s) {
+			return Property{}, fmt.Errorf("malformed parameter of %s", p.Name)
+		}
+
+		if p.Params == nil {
+			p.Params = make(map[string]string)
+		}
+		p.Params[name] = value.String()
+		i = j
+	}
+
+	p.Value = s[i+1:]
+	return p, nil
+}
@@ -0,0 +1,217 @@
+package ical
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []*Component
+		// wantErr is part of the error ending the stream, empty for io.EOF
+		wantErr string
+	}{
+		{
+			name:  "event",
+			input: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nUID:a\r\nSUMMARY:buy milk\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: []*Component{{Name: "VEVENT", Props: []Property{
+				{Name: "UID", Value: "a"},
+				{Name: "SUMMARY", Value: "buy milk"},
+			}}},
+		},
+		{
+			name:  "folded with space and tab, LF endings",
+			input: "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:buy\n  milk\n\tand eggs\nEND:VTODO\nEND:VCALENDAR\n",
+			want:  []*Component{{Name: "VTODO", Props: []Property{{Name: "SUMMARY", Value: "buy milkand eggs"}}}},
+		},
+		{
+			name:  "lower-case names and blank lines",
+			input: "begin:vcalendar\r\n\r\nbegin:vevent\r\nuid:a\r\n\r\nend:vevent\r\nend:vcalendar\r\n",
+			want:  []*Component{{Name: "VEVENT", Props: []Property{{Name: "UID", Value: "a"}}}},
+		},
+		{
+			name: "parameters",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" +
+				"DTSTART;tzid=Europe/Berlin:20250301T090000\r\n" +
+				"ATTENDEE;CN=\"Doe; Jane\";ROLE=REQ-PARTICIPANT:mailto:jane@example.com\r\n" +
+				"CATEGORIES;X-LIST=a,\"b:c\",d:x\r\n" +
+				"END:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: []*Component{{Name: "VEVENT", Props: []Property{
+				{Name: "DTSTART", Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20250301T090000"},
+				{Name: "ATTENDEE", Params: map[string]string{"CN": "Doe; Jane", "ROLE": "REQ-PARTICIPANT"}, Value: "mailto:jane@example.com"},
+				{Name: "CATEGORIES", Params: map[string]string{"X-LIST": "a,b:c,d"}, Value: "x"},
+			}}},
+		},
+		{
+			name: "nested components",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\n" +
+				"BEGIN:VALARM\r\nACTION:DISPLAY\r\nEND:VALARM\r\n" +
+				"END:VEVENT\r\nEND:VCALENDAR\r\n",
+			want: []*Component{{
+				Name:       "VEVENT",
+				Props:      []Property{{Name: "UID", Value: "a"}},
+				Components: []*Component{{Name: "VALARM", Props: []Property{{Name: "ACTION", Value: "DISPLAY"}}}},
+			}},
+		},
+		{
+			name: "several calendars",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n" +
+				"BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:b\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			want: []*Component{
+				{Name: "VEVENT", Props: []Property{{Name: "UID", Value: "a"}}},
+				{Name: "VTODO", Props: []Property{{Name: "UID", Value: "b"}}},
+			},
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: "no VCALENDAR found",
+		},
+		{
+			name:    "not a calendar",
+			input:   "BEGIN:VEVENT\r\nEND:VEVENT\r\n",
+			wantErr: "line 1: expected BEGIN:VCALENDAR",
+		},
+		{
+			name:    "calendar not closed",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VEVENT\r\n",
+			want:    []*Component{{Name: "VEVENT"}},
+			wantErr: "line 3: missing END:VCALENDAR",
+		},
+		{
+			name:    "component not closed",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\n",
+			wantErr: "line 3: missing END:VEVENT",
+		},
+		{
+			name:    "mismatched END",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VTODO\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 3: END:VTODO does not close VEVENT",
+		},
+		{
+			name:    "END without BEGIN",
+			input:   "BEGIN:VCALENDAR\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 2: unexpected END:VEVENT",
+		},
+		{
+			name:    "line without a colon",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 3: malformed content line",
+		},
+		{
+			name:    "parameter without a value",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;TZID:20250301T090000\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 3: malformed parameter of DTSTART",
+		},
+		{
+			name:    "line without a name",
+			input:   "BEGIN:VCALENDAR\r\n:value\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 2: malformed content line",
+		},
+		{
+			name:    "unterminated quote",
+			input:   "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nATTENDEE;CN=\"Jane:mailto:jane@example.com\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "line 3: unterminated quote in ATTENDEE",
+		},
+		{
+			name: "nested too deep",
+			input: "BEGIN:VCALENDAR\r\n" + strings.Repeat("BEGIN:X\r\n", maxDepth+1) +
+				strings.Repeat("END:X\r\n", maxDepth+1) + "END:VCALENDAR\r\n",
+			wantErr: "components nested deeper than 8",
+		},
+		{
+			name: "too many properties",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.Repeat("X-A:1\r\n", maxProps+1) +
+				"END:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "VEVENT has more than 1000 properties",
+		},
+		{
+			name: "too many components",
+			input: "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.Repeat("BEGIN:VALARM\r\nEND:VALARM\r\n", maxComponents+1) +
+				"END:VEVENT\r\nEND:VCALENDAR\r\n",
+			wantErr: "VEVENT holds more than 100 components",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(strings.NewReader(tt.input))
+			var got []*Component
+			var err error
+			for {
+				var c *Component
+				if c, err = dec.Next(); err != nil {
+					break
+				}
+				got = append(got, c)
+			}
+
+			if tt.wantErr == "" {
+				if !errors.Is(err, io.EOF) {
+					t.Fatalf("Next() = %v, want io.EOF", err)
+				}
+			} else if err == nil || errors.Is(err, io.EOF) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Next() = %v, want an error containing %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("components = %s, want %s", dump(got), dump(tt.want))
+			}
+		})
+	}
+}
+
+func TestPropertyTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		prop     Property
+		want     string
+		wantDate bool
+		wantErr  bool
+	}{
+		{name: "date", prop: Property{Name: "DTSTART", Params: map[string]string{"VALUE": "DATE"}, Value: "20250301"}, want: "2025-03-01T00:00:00Z", wantDate: true},
+		{name: "date without VALUE", prop: Property{Name: "DTSTART", Value: "20250301"}, want: "2025-03-01T00:00:00Z", wantDate: true},
+		{name: "utc", prop: Property{Name: "DTSTART", Value: "20250301T090000Z"}, want: "2025-03-01T09:00:00Z"},
+		{name: "floating read as utc", prop: Property{Name: "DTSTART", Value: "20250301T090000"}, want: "2025-03-01T09:00:00Z"},
+		{name: "tzid", prop: Property{Name: "DTSTART", Params: map[string]string{"TZID": "Europe/Berlin"}, Value: "20250301T090000"}, want: "2025-03-01T09:00:00+01:00"},
+		{name: "tzid with slash", prop: Property{Name: "DTSTART", Params: map[string]string{"TZID": "/Europe/Berlin"}, Value: "20250301T090000"}, want: "2025-03-01T09:00:00+01:00"},
+		{name: "unknown tzid", prop: Property{Name: "DTSTART", Params: map[string]string{"TZID": "W. Europe Standard Time"}, Value: "20250301T090000"}, wantErr: true},
+		{name: "invalid date", prop: Property{Name: "DTSTART", Params: map[string]string{"VALUE": "DATE"}, Value: "2025-03-01"}, wantErr: true},
+		{name: "invalid date-time", prop: Property{Name: "DTSTART", Value: "20250301T25"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, date, err := tt.prop.Time()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Time() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := got.Format("2006-01-02T15:04:05Z07:00"); s != tt.want || date != tt.wantDate {
+				t.Errorf("Time() = %s, %t, want %s, %t", s, date, tt.want, tt.wantDate)
+			}
+		})
+	}
+}
+
+// dump renders components for a failure message
+func dump(cs []*Component) string {
+	var b strings.Builder
+	for _, c := range cs {
+		b.WriteString("{" + c.Name)
+		for _, p := range c.Props {
+			b.WriteString(" " + p.Name + "=" + p.Value)
+		}
+		b.WriteString(" " + dump(c.Components) + "}")
+	}
+	return "[" + b.String() + "]"
+}
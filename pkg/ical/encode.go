@@ -0,0 +1,109 @@
+package ical
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxLineOctets is the length lines are folded at, CRLF left out
+const maxLineOctets = 75
+
+// Encoder writes content lines, folding them and ending them with CRLF.
+// The first error is kept and returned by Flush, writes after it are
+// dropped.
+type Encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+func (e *Encoder) Begin(name string) {
+	e.line("BEGIN:" + name)
+}
+
+func (e *Encoder) End(name string) {
+	e.line("END:" + name)
+}
+
+// Text writes a TEXT property, escaping value
+func (e *Encoder) Text(name, value string) {
+	e.Prop(Property{Name: name, Value: EscapeText(value)})
+}
+
+// Prop writes p, parameters in name order; values with a colon,
+// semicolon or comma are quoted
+func (e *Encoder) Prop(p Property) {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	names := make([]string, 0, len(p.Params))
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := p.Params[name]
+		b.WriteString(";" + name + "=")
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + strings.ReplaceAll(value, `"`, "") + `"`
+		}
+		b.WriteString(value)
+	}
+
+	b.WriteString(":" + p.Value)
+	e.line(b.String())
+}
+
+// Component writes c with everything nested in it
+func (e *Encoder) Component(c *Component) {
+	e.Begin(c.Name)
+	for _, p := range c.Props {
+		e.Prop(p)
+	}
+	for _, child := range c.Components {
+		e.Component(child)
+	}
+	e.End(c.Name)
+}
+
+// Flush hands the buffered lines to the underlying writer
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.w.Flush()
+	return e.err
+}
+
+// line writes s folded into lines of at most 75 octets, never splitting a
+// UTF-8 sequence; continuation lines start with a space
+func (e *Encoder) line(s string) {
+	if e.err != nil {
+		return
+	}
+
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		e.write(s[:cut] + "\r\n ")
+		s = s[cut:]
+		// the leading space counts towards the next line
+		limit = maxLineOctets - 1
+	}
+	e.write(s + "\r\n")
+}
+
+func (e *Encoder) write(s string) {
+	if e.err == nil {
+		_, e.err = e.w.WriteString(s)
+	}
+}
@@ -0,0 +1,96 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEncoderFolding(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		lines int
+	}{
+		{name: "short", value: "buy milk", lines: 1},
+		{name: "exactly one line", value: strings.Repeat("a", maxLineOctets-len("SUMMARY:")), lines: 1},
+		{name: "one octet over", value: strings.Repeat("a", maxLineOctets-len("SUMMARY:")+1), lines: 2},
+		{name: "several lines", value: strings.Repeat("a", 300), lines: 5},
+		{name: "multibyte runes", value: strings.Repeat("ü€😀", 40), lines: 6},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf)
+			enc.Prop(Property{Name: "SUMMARY", Value: tt.value})
+			if err := enc.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			out := buf.String()
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end with CRLF", out)
+			}
+			lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+			if len(lines) != tt.lines {
+				t.Errorf("folded into %d lines, want %d", len(lines), tt.lines)
+			}
+			for i, line := range lines {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d has %d octets", i+1, len(line))
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence", i+1)
+				}
+				if i > 0 && line[0] != ' ' {
+					t.Errorf("continuation line %d does not start with a space", i+1)
+				}
+			}
+
+			// the decoder unfolds it back
+			c, err := NewDecoder(strings.NewReader("BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + out + "END:VEVENT\r\nEND:VCALENDAR\r\n")).Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.Prop("SUMMARY").Value; got != tt.value {
+				t.Errorf("unfolded to %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestEncoderParams(t *testing.T) {
+	tests := []struct {
+		name string
+		prop Property
+		want string
+	}{
+		{name: "no params", prop: Property{Name: "UID", Value: "a"}, want: "UID:a\r\n"},
+		{
+			name: "params in name order",
+			prop: Property{Name: "DTSTART", Params: map[string]string{"VALUE": "DATE-TIME", "TZID": "Europe/Berlin"}, Value: "20250301T090000"},
+			want: "DTSTART;TZID=Europe/Berlin;VALUE=DATE-TIME:20250301T090000\r\n",
+		},
+		{
+			name: "delimiters quoted",
+			prop: Property{Name: "ATTENDEE", Params: map[string]string{"CN": `Doe, "Jane"`}, Value: "mailto:jane@example.com"},
+			want: "ATTENDEE;CN=\"Doe, Jane\":mailto:jane@example.com\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			enc := NewEncoder(&buf)
+			enc.Prop(tt.prop)
+			if err := enc.Flush(); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
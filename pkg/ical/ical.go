@@ -0,0 +1,59 @@
+// Package ical reads and writes iCalendar (RFC 5545) streams: content
+// lines with their folding, escaping and parameters, the date and time
+// forms, and VTIMEZONE components built from the tz database.
+package ical
+
+import "strings"
+
+// Property is a content line such as DTSTART;TZID=Europe/Berlin:20250301T090000
+type Property struct {
+	Name string
+	// Params holds the parameters by upper-case name, a list value kept
+	// as written
+	Params map[string]string
+	// Value is the raw value, TEXT values still escaped
+	Value string
+}
+
+// Param returns the named parameter, empty if unset
+func (p Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Text returns the value of a TEXT property unescaped
+func (p Property) Text() string {
+	return UnescapeText(p.Value)
+}
+
+// Component is a BEGIN/END block such as VEVENT with its properties and
+// nested components
+type Component struct {
+	Name       string
+	Props      []Property
+	Components []*Component
+}
+
+// Prop returns the first property of that name, nil if there is none
+func (c *Component) Prop(name string) *Property {
+	name = strings.ToUpper(name)
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// EscapeText escapes a TEXT value
+func EscapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+// UnescapeText reverses EscapeText
+func UnescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
@@ -0,0 +1,50 @@
+package ical
+
+import "testing"
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "plain", text: "buy milk", want: "buy milk"},
+		{name: "comma and semicolon", text: "milk, eggs; bread", want: `milk\, eggs\; bread`},
+		{name: "backslash", text: `C:\todo`, want: `C:\\todo`},
+		{name: "newline", text: "line one\nline two", want: `line one\nline two`},
+		{name: "crlf", text: "line one\r\nline two", want: `line one\nline two`},
+		{name: "escaped sequence stays literal", text: `\n`, want: `\\n`},
+		{name: "colon is left alone", text: "at: home", want: "at: home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EscapeText(tt.text)
+			if got != tt.want {
+				t.Errorf("EscapeText(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnescapeText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "buy milk", want: "buy milk"},
+		{value: `milk\, eggs\; bread`, want: "milk, eggs; bread"},
+		{value: `C:\\todo`, want: `C:\todo`},
+		{value: `one\ntwo`, want: "one\ntwo"},
+		{value: `one\Ntwo`, want: "one\ntwo"},
+		{value: `\\n`, want: `\n`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if got := UnescapeText(tt.value); got != tt.want {
+				t.Errorf("UnescapeText(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
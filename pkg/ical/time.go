@@ -0,0 +1,164 @@
+package ical
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Layouts of RFC 5545 dates, date-times and UTC offsets
+const (
+	dateLayout    = "20060102"
+	localLayout   = "20060102T150405"
+	utcLayout     = "20060102T150405Z"
+	offsetLayout  = "-0700"
+	secondsLayout = "-070000"
+)
+
+// firstYear is the year VTIMEZONE rules start in
+const firstYear = 1970
+
+const daysInWeek = 7
+
+// Date is a DATE property such as DTSTART;VALUE=DATE:20250301
+func Date(name string, t time.Time) Property {
+	return Property{Name: name, Params: map[string]string{"VALUE": "DATE"}, Value: t.Format(dateLayout)}
+}
+
+// DateTime is a DATE-TIME property, in UTC unless loc is given, in which
+// case it is written as local time with a TZID
+func DateTime(name string, t time.Time, loc *time.Location) Property {
+	if loc == nil || loc == time.UTC {
+		return Property{Name: name, Value: t.UTC().Format(utcLayout)}
+	}
+	return Property{Name: name, Params: map[string]string{"TZID": loc.String()}, Value: t.In(loc).Format(localLayout)}
+}
+
+// Time reads a DATE or DATE-TIME property, reporting whether it was a
+// date. A TZID must name a zone of the tz database; a floating time, one
+// without zone, is read as UTC.
+func (p Property) Time() (t time.Time, date bool, err error) {
+	value := strings.TrimSpace(p.Value)
+	if strings.EqualFold(p.Param("VALUE"), "DATE") || len(value) == len(dateLayout) {
+		t, err = time.Parse(dateLayout, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("%s: invalid date %q", p.Name, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err = time.Parse(utcLayout, value)
+	} else {
+		loc := time.UTC
+		if tzid := p.Param("TZID"); tzid != "" {
+			// some producers prefix tz database names with a slash
+			if loc, err = time.LoadLocation(strings.TrimPrefix(tzid, "/")); err != nil {
+				return time.Time{}, false, fmt.Errorf("%s: unknown TZID %q", p.Name, tzid)
+			}
+		}
+		t, err = time.ParseInLocation(localLayout, value, loc)
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("%s: invalid date-time %q", p.Name, value)
+	}
+	return t, false, nil
+}
+
+// VTimezone describes loc as a VTIMEZONE. The daylight saving rules in
+// force this year are written as yearly rules starting in 1970, so the
+// description only holds for the years those rules apply to.
+func VTimezone(loc *time.Location) *Component {
+	tz := &Component{Name: "VTIMEZONE", Props: []Property{{Name: "TZID", Value: loc.String()}}}
+
+	start := time.Date(time.Now().In(loc).Year(), time.January, 1, 0, 0, 0, 0, loc)
+	changes := transitions(start, start.AddDate(1, 0, 0))
+	if len(changes) == 0 {
+		name, offset := start.Zone()
+		tz.Components = append(tz.Components, &Component{Name: "STANDARD", Props: []Property{
+			{Name: "DTSTART", Value: time.Date(firstYear, time.January, 1, 0, 0, 0, 0, time.UTC).Format(localLayout)},
+			{Name: "TZOFFSETFROM", Value: formatOffset(offset)},
+			{Name: "TZOFFSETTO", Value: formatOffset(offset)},
+			{Name: "TZNAME", Value: EscapeText(name)},
+		}})
+		return tz
+	}
+
+	for _, at := range changes {
+		_, from := at.Add(-time.Second).Zone()
+		name, to := at.Zone()
+
+		// the onset is given in the wall clock time before the change
+		onset := at.In(time.FixedZone("", from))
+		ordinal := (onset.Day()-1)/daysInWeek + 1
+		if onset.Day()+daysInWeek > daysIn(onset.Year(), onset.Month()) {
+			ordinal = -1
+		}
+		first := nthWeekday(firstYear, onset.Month(), onset.Weekday(), ordinal)
+
+		kind := "STANDARD"
+		if at.IsDST() {
+			kind = "DAYLIGHT"
+		}
+		tz.Components = append(tz.Components, &Component{Name: kind, Props: []Property{
+			{Name: "DTSTART", Value: time.Date(first.Year(), first.Month(), first.Day(),
+				onset.Hour(), onset.Minute(), onset.Second(), 0, time.UTC).Format(localLayout)},
+			{Name: "RRULE", Value: fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s",
+				onset.Month(), ordinal, strings.ToUpper(onset.Weekday().String()[:2]))},
+			{Name: "TZOFFSETFROM", Value: formatOffset(from)},
+			{Name: "TZOFFSETTO", Value: formatOffset(to)},
+			{Name: "TZNAME", Value: EscapeText(name)},
+		}})
+	}
+	return tz
+}
+
+// transitions finds the instants in [start, end) at which the UTC offset
+// of the zone of start changes, at most once a day
+func transitions(start, end time.Time) []time.Time {
+	var changes []time.Time
+	_, offset := start.Zone()
+	for t := start; t.Before(end); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		if _, o := next.Zone(); o == offset {
+			continue
+		}
+
+		// bisect down to the second the offset changes
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		changes = append(changes, hi)
+		_, offset = next.Zone()
+	}
+	return changes
+}
+
+// nthWeekday is the ordinal-th weekday of the month, -1 being the last
+func nthWeekday(year int, month time.Month, weekday time.Weekday, ordinal int) time.Time {
+	if ordinal < 0 {
+		last := time.Date(year, month, daysIn(year, month), 0, 0, 0, 0, time.UTC)
+		return last.AddDate(0, 0, -int(last.Weekday()-weekday+daysInWeek)%daysInWeek)
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 0, int(weekday-first.Weekday()+daysInWeek)%daysInWeek+(ordinal-1)*daysInWeek)
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// formatOffset writes a UTC offset as +hhmm, or +hhmmss when it has seconds
+func formatOffset(seconds int) string {
+	layout := offsetLayout
+	if seconds%60 != 0 {
+		layout = secondsLayout
+	}
+	return time.Date(firstYear, time.January, 1, 0, 0, 0, 0, time.FixedZone("", seconds)).Format(layout)
+}
@@ -1,37 +1,224 @@
 package migrator
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
+	"net/url"
 
 	"ice/config"
+	"ice/internal/migration"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 )
 
-func RunMigrations(cfg config.MySQLConfig) error {
-	dsn := fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true",
-		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
+// Migrator applies the schema migrations embedded in the binary, or the
+// ones found under MySQLConfig.MigrationsPath
+type Migrator struct {
+	m *migrate.Migrate
+	// path overrides the embedded migrations when set
+	path string
+}
+
+// Migration is a migration shipped with the binary
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// Status describes the schema of the database against the shipped
+// migrations
+type Status struct {
+	// Version is the applied version, zero when none has run
+	Version uint
+	// Dirty is set when the migration to Version failed half-way
+	Dirty bool
+	// Latest is the highest shipped version
+	Latest     uint
+	Migrations []Migration
+}
+
+// DirtyError reports a database left dirty by a failed migration, which
+// has to be repaired by hand before migrating again
+type DirtyError struct {
+	Version uint
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("database is dirty at version %d: migration %d failed half-way; "+
+		"repair the schema by hand, then run \"migrate force %d\" if the migration is fully applied "+
+		"or \"migrate force <previous version>\" to retry it", e.Version, e.Version, e.Version)
+}
+
+func New(cfg config.MySQLConfig) (*Migrator, error) {
+	// migrations run in UTC like the application, so CURRENT_TIMESTAMP
+	// and NOW() in backfills store the same clock the service reads
+	dsn := fmt.Sprintf("mysql://%s:%s@tcp(%s:%s)/%s?multiStatements=true&time_zone=%s",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Database, url.QueryEscape("'+00:00'"))
+
+	src, name, err := openSource(cfg.MigrationsPath)
+	if err != nil {
+		return nil, err
+	}
 
-	m, err := migrate.New(
-		"file://internal/migration/mysql",
-		dsn,
-	)
+	m, err := migrate.NewWithSourceInstance(name, src, dsn)
 	if err != nil {
-		return fmt.Errorf("migration init failed: %w", err)
+		src.Close()
+		return nil, fmt.Errorf("migration init failed: %w", err)
 	}
-	defer func() {
-		if sourceErr, dbErr := m.Close(); sourceErr != nil || dbErr != nil {
-			log.Printf("migration close error: source=%v, db=%v", sourceErr, dbErr)
+	return &Migrator{m: m, path: cfg.MigrationsPath}, nil
+}
+
+// openSource reads migrations from path when set, otherwise from the
+// files embedded in the binary
+func openSource(path string) (source.Driver, string, error) {
+	if path != "" {
+		src, err := source.Open("file://" + path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open migrations in %s: %w", path, err)
 		}
-	}()
+		return src, "file", nil
+	}
 
-	if err := m.Up(); err != nil && err.Error() != "no change" {
-		return fmt.Errorf("migration up failed: %w", err)
+	src, err := iofs.New(migration.MySQL, "mysql")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open embedded migrations: %w", err)
+	}
+	return src, "iofs", nil
+}
+
+func RunMigrations(cfg config.MySQLConfig) error {
+	m, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil {
+		return err
 	}
 
 	log.Println("migration complete")
 	return nil
 }
+
+// Up applies all pending migrations
+func (m *Migrator) Up() error {
+	return m.wrap("up", m.m.Up())
+}
+
+// Down reverts every applied migration
+func (m *Migrator) Down() error {
+	return m.wrap("down", m.m.Down())
+}
+
+// Steps applies n migrations, or reverts -n when n is negative
+func (m *Migrator) Steps(n int) error {
+	return m.wrap("steps", m.m.Steps(n))
+}
+
+// Goto migrates up or down to version
+func (m *Migrator) Goto(version uint) error {
+	return m.wrap("goto", m.m.Migrate(version))
+}
+
+// Force records version as applied and clean without running anything,
+// -1 meaning no migration applied
+func (m *Migrator) Force(version int) error {
+	return m.wrap("force", m.m.Force(version))
+}
+
+// Version returns the applied version, zero when none has run
+func (m *Migrator) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Status reports the applied version against the shipped migrations
+func (m *Migrator) Status() (Status, error) {
+	version, dirty, err := m.Version()
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	migrations, err := Available(m.path)
+	if err != nil {
+		return Status{}, err
+	}
+
+	st := Status{Version: version, Dirty: dirty, Migrations: migrations}
+	if len(migrations) > 0 {
+		st.Latest = migrations[len(migrations)-1].Version
+	}
+	return st, nil
+}
+
+func (m *Migrator) Close() {
+	if sourceErr, dbErr := m.m.Close(); sourceErr != nil || dbErr != nil {
+		log.Printf("migration close error: source=%v, db=%v", sourceErr, dbErr)
+	}
+}
+
+// wrap treats "no change" as success and turns a dirty database into a
+// DirtyError
+func (m *Migrator) wrap(op string, err error) error {
+	if err == nil || errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return &DirtyError{Version: uint(dirty.Version)}
+	}
+	return fmt.Errorf("migration %s failed: %w", op, err)
+}
+
+// Available lists the migrations in version order, read from path when
+// set, otherwise the ones embedded in the binary
+func Available(path string) ([]Migration, error) {
+	src, _, err := openSource(path)
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read first migration: %w", err)
+	}
+
+	var migrations []Migration
+	for {
+		r, name, err := src.ReadUp(version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, err)
+		}
+		r.Close()
+		migrations = append(migrations, Migration{Version: version, Name: name})
+
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return migrations, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration after %d: %w", version, err)
+		}
+		version = next
+	}
+}
+
+// LatestVersion returns the highest migration version, i.e. the schema
+// version the code expects; path is as for Available
+func LatestVersion(path string) (uint, error) {
+	migrations, err := Available(path)
+	if err != nil {
+		return 0, err
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
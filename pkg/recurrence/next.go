@@ -0,0 +1,150 @@
+package recurrence
+
+import (
+	"sort"
+	"time"
+)
+
+// maxMonths bounds the search for a month holding a matching day, e.g. a
+// fifth Monday or a 31st
+const maxMonths = 60
+
+// Next returns the occurrence following prev, the n-th occurrence of the
+// series (1 for the first). Occurrences keep the time of day and location
+// of prev, which is the zone a date UNTIL is read in. ok is false once the
+// series has ended.
+func (r Rule) Next(prev time.Time, n int) (time.Time, bool) {
+	if r.Count > 0 && n >= r.Count {
+		return time.Time{}, false
+	}
+
+	next, ok := r.next(prev)
+	if !ok || r.after(next) {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// after reports whether t is past UNTIL
+func (r Rule) after(t time.Time) bool {
+	if r.Until.IsZero() {
+		return false
+	}
+	if r.UntilDate {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).After(r.Until)
+	}
+	return t.After(r.Until)
+}
+
+func (r Rule) next(prev time.Time) (time.Time, bool) {
+	interval := max(r.Interval, 1)
+
+	switch r.Freq {
+	case Daily:
+		// an interval that is not a multiple of 7, which Parse rejects
+		// with BYDAY, reaches every weekday within 7 steps
+		for i := 1; i <= 7; i++ {
+			t := prev.AddDate(0, 0, i*interval)
+			if r.matchesWeekday(t) {
+				return t, true
+			}
+		}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return prev.AddDate(0, 0, 7*interval), true
+		}
+		// weeks start on Monday; ByDay is kept in that order
+		start := prev.AddDate(0, 0, -weekdayIndex(prev.Weekday()))
+		for _, d := range r.ByDay {
+			if t := start.AddDate(0, 0, weekdayIndex(d.Weekday)); t.After(prev) {
+				return t, true
+			}
+		}
+		start = start.AddDate(0, 0, 7*interval)
+		return start.AddDate(0, 0, weekdayIndex(r.ByDay[0].Weekday)), true
+
+	case Monthly:
+		year, month, _ := prev.Date()
+		for i := 0; i < maxMonths; i++ {
+			for _, t := range r.monthDays(prev, year, month+time.Month(i*interval)) {
+				if t.After(prev) {
+					return t, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (r Rule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDays lists the occurrences in the given month in chronological
+// order, at the time of day of prev. Without BYDAY that is the day of the
+// month of prev, skipped in months too short to hold it.
+func (r Rule) monthDays(prev time.Time, year int, month time.Month) []time.Time {
+	hour, minute, sec := prev.Clock()
+	date := func(day int) time.Time {
+		return time.Date(year, month, day, hour, minute, sec, prev.Nanosecond(), prev.Location())
+	}
+	// normalise year and month, which may overflow
+	first := date(1)
+	year, month = first.Year(), first.Month()
+	days := first.AddDate(0, 1, -1).Day()
+
+	if len(r.ByDay) == 0 {
+		if prev.Day() > days {
+			return nil
+		}
+		return []time.Time{date(prev.Day())}
+	}
+
+	matched := make(map[int]bool)
+	for _, d := range r.ByDay {
+		// first day of the month falling on d.Weekday
+		firstDay := 1 + (int(d.Weekday)-int(first.Weekday())+7)%7
+		switch {
+		case d.Ordinal == 0:
+			for day := firstDay; day <= days; day += 7 {
+				matched[day] = true
+			}
+		case d.Ordinal > 0:
+			if day := firstDay + (d.Ordinal-1)*7; day <= days {
+				matched[day] = true
+			}
+		default:
+			lastDay := firstDay + (days-firstDay)/7*7
+			if day := lastDay + (d.Ordinal+1)*7; day >= 1 {
+				matched[day] = true
+			}
+		}
+	}
+
+	list := make([]int, 0, len(matched))
+	for day := range matched {
+		list = append(list, day)
+	}
+	sort.Ints(list)
+
+	occurrences := make([]time.Time, len(list))
+	for i, day := range list {
+		occurrences[i] = date(day)
+	}
+	return occurrences
+}
+
+// weekdayIndex numbers weekdays from Monday, the iCalendar default WKST
+func weekdayIndex(d time.Weekday) int {
+	return (int(d) + 6) % 7
+}
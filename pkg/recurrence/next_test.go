@@ -0,0 +1,160 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	var berlin, tokyo, newYork *time.Location
+	for name, loc := range map[string]**time.Location{"Europe/Berlin": &berlin, "Asia/Tokyo": &tokyo, "America/New_York": &newYork} {
+		var err error
+		if *loc, err = time.LoadLocation(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	at := func(year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		// want lists the occurrences following start, a series ending
+		// before len(want)+1 occurrences is reported by wantEnd
+		want    []time.Time
+		wantEnd bool
+	}{
+		{
+			name:  "daily",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: at(2025, time.January, 30, 9),
+			want:  []time.Time{at(2025, time.February, 1, 9), at(2025, time.February, 3, 9)},
+		},
+		{
+			name:  "daily on weekdays",
+			rule:  "FREQ=DAILY;BYDAY=MO,WE,FR",
+			start: at(2025, time.January, 6, 9),
+			want:  []time.Time{at(2025, time.January, 8, 9), at(2025, time.January, 10, 9), at(2025, time.January, 13, 9)},
+		},
+		{
+			name:  "daily interval walks to the weekday",
+			rule:  "FREQ=DAILY;INTERVAL=3;BYDAY=MO",
+			start: at(2025, time.January, 7, 9),
+			want:  []time.Time{at(2025, time.January, 13, 9), at(2025, time.February, 3, 9)},
+		},
+		{
+			name:  "weekly",
+			rule:  "FREQ=WEEKLY",
+			start: at(2025, time.January, 6, 9),
+			want:  []time.Time{at(2025, time.January, 13, 9)},
+		},
+		{
+			name:  "every other week on two days",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+			start: at(2025, time.January, 6, 9),
+			want:  []time.Time{at(2025, time.January, 9, 9), at(2025, time.January, 20, 9), at(2025, time.January, 23, 9)},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY",
+			start: at(2025, time.January, 31, 9),
+			want:  []time.Time{at(2025, time.March, 31, 9), at(2025, time.May, 31, 9), at(2025, time.July, 31, 9)},
+		},
+		{
+			name:  "second tuesday",
+			rule:  "FREQ=MONTHLY;BYDAY=2TU",
+			start: at(2025, time.January, 14, 9),
+			want:  []time.Time{at(2025, time.February, 11, 9), at(2025, time.March, 11, 9)},
+		},
+		{
+			name:  "last friday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: at(2025, time.January, 31, 9),
+			want:  []time.Time{at(2025, time.February, 28, 9), at(2025, time.March, 28, 9)},
+		},
+		{
+			name:  "fifth monday skips months without one",
+			rule:  "FREQ=MONTHLY;BYDAY=5MO",
+			start: at(2025, time.March, 31, 9),
+			want:  []time.Time{at(2025, time.June, 30, 9)},
+		},
+		{
+			name:    "count",
+			rule:    "FREQ=DAILY;COUNT=3",
+			start:   at(2025, time.January, 1, 9),
+			want:    []time.Time{at(2025, time.January, 2, 9), at(2025, time.January, 3, 9)},
+			wantEnd: true,
+		},
+		{
+			name:    "until date includes its day",
+			rule:    "FREQ=DAILY;UNTIL=20250103",
+			start:   at(2025, time.January, 1, 23),
+			want:    []time.Time{at(2025, time.January, 2, 23), at(2025, time.January, 3, 23)},
+			wantEnd: true,
+		},
+		{
+			name:    "until date in a zone east of UTC",
+			rule:    "FREQ=DAILY;UNTIL=20250110",
+			start:   time.Date(2025, time.January, 9, 0, 0, 0, 0, berlin),
+			want:    []time.Time{time.Date(2025, time.January, 10, 0, 0, 0, 0, berlin)},
+			wantEnd: true,
+		},
+		{
+			name:    "until date in a zone east of UTC, late in the day",
+			rule:    "FREQ=DAILY;UNTIL=20250110",
+			start:   time.Date(2025, time.January, 9, 23, 30, 0, 0, tokyo),
+			want:    []time.Time{time.Date(2025, time.January, 10, 23, 30, 0, 0, tokyo)},
+			wantEnd: true,
+		},
+		{
+			name:    "until date in a zone west of UTC",
+			rule:    "FREQ=DAILY;UNTIL=20250110",
+			start:   time.Date(2025, time.January, 9, 20, 0, 0, 0, newYork),
+			want:    []time.Time{time.Date(2025, time.January, 10, 20, 0, 0, 0, newYork)},
+			wantEnd: true,
+		},
+		{
+			name:    "until time",
+			rule:    "FREQ=WEEKLY;UNTIL=20250113T085959Z",
+			start:   at(2025, time.January, 6, 9),
+			wantEnd: true,
+		},
+		{
+			name:  "wall clock kept across daylight saving",
+			rule:  "FREQ=DAILY",
+			start: time.Date(2025, time.March, 29, 9, 0, 0, 0, berlin),
+			want:  []time.Time{time.Date(2025, time.March, 30, 9, 0, 0, 0, berlin), time.Date(2025, time.March, 31, 9, 0, 0, 0, berlin)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+
+			prev := tt.start
+			for i, want := range tt.want {
+				got, ok := r.Next(prev, i+1)
+				if !ok {
+					t.Fatalf("occurrence %d: series ended, want %s", i+2, want)
+				}
+				if !got.Equal(want) {
+					t.Fatalf("occurrence %d: got %s, want %s", i+2, got, want)
+				}
+				prev = got
+			}
+
+			got, ok := r.Next(prev, len(tt.want)+1)
+			if tt.wantEnd && ok {
+				t.Errorf("series continues with %s, want it ended", got)
+			}
+			if !tt.wantEnd && !ok {
+				t.Errorf("series ended after %d occurrences", len(tt.want)+1)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// Package recurrence implements the subset of iCalendar (RFC 5545) RRULEs
+// used by recurring todos: DAILY, WEEKLY and MONTHLY frequencies with
+// INTERVAL, BYDAY, UNTIL and COUNT.
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Day is a BYDAY entry. Ordinal selects the nth weekday of the month
+// (-1 being the last) and is only allowed with MONTHLY, zero means every
+// such weekday.
+type Day struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []Day
+	// Until is the last instant an occurrence may fall on, zero if unset.
+	// With UntilDate it is a date, at midnight UTC, and occurrences may
+	// fall on any time of that day in the zone of the series.
+	Until     time.Time
+	UntilDate bool
+	// Count is the total number of occurrences, zero if unset
+	Count int
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// untilLayouts are the UTC date-time and date forms accepted for UNTIL
+var untilLayouts = []string{"20060102T150405Z", "20060102"}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", with or
+// without the "RRULE:" prefix
+func Parse(s string) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("empty recurrence rule")
+	}
+
+	r := Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("malformed rule part %q", part)
+		}
+		if seen[name] {
+			return Rule{}, fmt.Errorf("%s given more than once", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			if r.Freq != Daily && r.Freq != Weekly && r.Freq != Monthly {
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = positive(name, value)
+		case "COUNT":
+			r.Count, err = positive(name, value)
+		case "UNTIL":
+			r.Until, r.UntilDate, err = parseUntil(value)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		default:
+			err = fmt.Errorf("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return Rule{}, err
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if !r.Until.IsZero() && r.Count > 0 {
+		return Rule{}, fmt.Errorf("UNTIL and COUNT cannot be combined")
+	}
+	if r.Freq == Daily && len(r.ByDay) > 0 && r.Interval%7 == 0 {
+		// every occurrence would fall on the weekday of the first one
+		return Rule{}, fmt.Errorf("BYDAY with FREQ=DAILY needs an INTERVAL that is not a multiple of 7, use FREQ=WEEKLY")
+	}
+	if r.Freq != Monthly {
+		for _, d := range r.ByDay {
+			if d.Ordinal != 0 {
+				return Rule{}, fmt.Errorf("BYDAY ordinals are only allowed with FREQ=MONTHLY")
+			}
+		}
+	}
+	return r, nil
+}
+
+func positive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", name)
+	}
+	return n, nil
+}
+
+// parseUntil reads UNTIL, reporting whether it is a date
+func parseUntil(value string) (time.Time, bool, error) {
+	for _, layout := range untilLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout == untilLayouts[1], nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("UNTIL must look like 20250131T235959Z or 20250131")
+}
+
+func parseByDay(value string) ([]Day, error) {
+	var days []Day
+	seen := make(map[Day]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY entry %q", item)
+		}
+
+		code, ordinal := item[len(item)-2:], item[:len(item)-2]
+		wd, ok := weekdays[code]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY entry %q", item)
+		}
+
+		d := Day{Weekday: wd}
+		if ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY entry %q", item)
+			}
+			d.Ordinal = n
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].Weekday != days[j].Weekday {
+			return weekdayIndex(days[i].Weekday) < weekdayIndex(days[j].Weekday)
+		}
+		return days[i].Ordinal < days[j].Ordinal
+	})
+	return days, nil
+}
+
+// String renders the rule in canonical form, parts in a fixed order and
+// defaults omitted
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayCodes[d.Weekday]
+			if d.Ordinal != 0 {
+				days[i] = strconv.Itoa(d.Ordinal) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	switch {
+	case r.UntilDate:
+		parts = append(parts, "UNTIL="+r.Until.Format(untilLayouts[1]))
+	case !r.Until.IsZero():
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(untilLayouts[0]))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,56 @@
+package recurrence
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "daily", in: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{name: "prefix and case", in: "RRULE:freq=weekly;interval=2;byday=th,mo", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH"},
+		{name: "default interval omitted", in: "FREQ=MONTHLY;INTERVAL=1", want: "FREQ=MONTHLY"},
+		{name: "duplicate days dropped", in: "FREQ=WEEKLY;BYDAY=MO,MO,FR", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{name: "ordinals", in: "FREQ=MONTHLY;BYDAY=-1FR,2TU", want: "FREQ=MONTHLY;BYDAY=2TU,-1FR"},
+		{name: "count", in: "FREQ=DAILY;COUNT=10", want: "FREQ=DAILY;COUNT=10"},
+		{name: "until date stays a date", in: "FREQ=DAILY;UNTIL=20250131", want: "FREQ=DAILY;UNTIL=20250131"},
+		{name: "until time", in: "FREQ=DAILY;UNTIL=20250131T120000Z", want: "FREQ=DAILY;UNTIL=20250131T120000Z"},
+		{name: "daily with byday", in: "FREQ=DAILY;INTERVAL=3;BYDAY=MO", want: "FREQ=DAILY;INTERVAL=3;BYDAY=MO"},
+
+		{name: "empty", in: "", wantErr: true},
+		{name: "missing freq", in: "INTERVAL=2", wantErr: true},
+		{name: "yearly", in: "FREQ=YEARLY", wantErr: true},
+		{name: "zero interval", in: "FREQ=DAILY;INTERVAL=0", wantErr: true},
+		{name: "negative count", in: "FREQ=DAILY;COUNT=-1", wantErr: true},
+		{name: "count and until", in: "FREQ=DAILY;COUNT=2;UNTIL=20250131", wantErr: true},
+		{name: "part twice", in: "FREQ=DAILY;FREQ=WEEKLY", wantErr: true},
+		{name: "unknown part", in: "FREQ=DAILY;BYHOUR=9", wantErr: true},
+		{name: "malformed part", in: "FREQ=DAILY;COUNT", wantErr: true},
+		{name: "bad weekday", in: "FREQ=WEEKLY;BYDAY=XX", wantErr: true},
+		{name: "ordinal out of range", in: "FREQ=MONTHLY;BYDAY=6MO", wantErr: true},
+		{name: "ordinal with weekly", in: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{name: "bad until", in: "FREQ=DAILY;UNTIL=2025-01-31", wantErr: true},
+		{name: "daily byday every week", in: "FREQ=DAILY;INTERVAL=7;BYDAY=MO", wantErr: true},
+		{name: "daily byday every two weeks", in: "FREQ=DAILY;INTERVAL=14;BYDAY=MO,TU", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %s, want an error", tt.in, r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
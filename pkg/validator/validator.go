@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"ice/pkg/recurrence"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -12,11 +14,23 @@ type Validator struct {
 }
 
 func New() *Validator {
+	validate := validator.New()
+	validate.RegisterValidation("rrule", validateRRule)
 	return &Validator{
-		validate: validator.New(),
+		validate: validate,
 	}
 }
 
+// validateRRule accepts an empty value or a supported recurrence rule
+func validateRRule(fl validator.FieldLevel) bool {
+	s := fl.Field().String()
+	if s == "" {
+		return true
+	}
+	_, err := recurrence.Parse(s)
+	return err == nil
+}
+
 func (v *Validator) Validate(i interface{}) error {
 	if err := v.validate.Struct(i); err != nil {
 		var errors []string
@@ -30,12 +44,25 @@ func (v *Validator) Validate(i interface{}) error {
 
 func getValidationError(err validator.FieldError) string {
 	switch err.Tag() {
-	case "required":
+	case "required", "required_unless":
 		return "is required"
+	case "excluded_if":
+		return "must not be set"
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", err.Param())
+	case "gte":
+		return fmt.Sprintf("must be at least %s", err.Param())
 	case "min":
 		return fmt.Sprintf("must be at least %s characters", err.Param())
 	case "max":
 		return fmt.Sprintf("must be at most %s characters", err.Param())
+	case "timezone":
+		return "must be an IANA time zone such as Europe/Berlin"
+	case "rrule":
+		if _, perr := recurrence.Parse(fmt.Sprint(err.Value())); perr != nil {
+			return fmt.Sprintf("must be a valid recurrence rule: %v", perr)
+		}
+		return "must be a valid recurrence rule"
 	default:
 		return fmt.Sprintf("failed validation: %s", err.Tag())
 	}